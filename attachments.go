@@ -0,0 +1,185 @@
+package libgojira
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+type SyncOptions struct {
+	DryRun   bool //Only compute the plan
+	Delete   bool //Delete attachments no local file is named after
+	Checksum bool //Compare the content of files of the same size, downloading the attachments
+}
+
+type SyncAction string
+
+const (
+	SyncUpload  SyncAction = "upload"  //New local file
+	SyncReplace SyncAction = "replace" //Changed local file: upload it, then delete the outdated attachments
+	SyncSkip    SyncAction = "skip"    //Already attached
+	SyncDelete  SyncAction = "delete"  //Attachment absent locally
+)
+
+type SyncStep struct {
+	Action   SyncAction
+	Filename string
+	Local    string        //Path of the local file, empty for deletions
+	Remote   IssueFileList //Attachments replaced, deleted or matching the local file
+	Uploaded *IssueFile    //Attachment created, unless on a dry run
+	Err      error
+}
+
+//Steps of a synchronization, sorted by filename.
+type SyncPlan []*SyncStep
+
+//Returns the first error met while carrying out the plan.
+func (sp SyncPlan) Err() error {
+	for _, step := range sp {
+		if step.Err != nil {
+			return step.Err
+		}
+	}
+	return nil
+}
+
+//Makes the attachments of an issue mirror the regular files of dir, subdirectories excluded.
+//A local file is considered attached when an attachment of the same name has the same size
+//and, with the Checksum option, the same content, so running it again is harmless.
+//The returned plan tells what was done, or what would be done on a dry run;
+//the error is that of the first step which failed.
+func (jc *JiraClient) SyncAttachments(issueKey string, dir string, opts *SyncOptions) (SyncPlan, error) {
+	return jc.SyncAttachmentsContext(context.Background(), issueKey, dir, opts)
+}
+
+func (jc *JiraClient) SyncAttachmentsContext(ctx context.Context, issueKey string, dir string, opts *SyncOptions) (SyncPlan, error) {
+	plan, err := jc.planSync(ctx, issueKey, dir, opts)
+	if err != nil || opts.DryRun {
+		return plan, err
+	}
+	for _, step := range plan {
+		if ctx.Err() != nil {
+			return plan, ctx.Err()
+		}
+		switch step.Action {
+		case SyncUpload, SyncReplace:
+			step.Err = jc.syncUpload(ctx, issueKey, step)
+		case SyncDelete:
+			step.Err = jc.syncDelete(ctx, step.Remote)
+		}
+	}
+	return plan, plan.Err()
+}
+
+func (jc *JiraClient) planSync(ctx context.Context, issueKey string, dir string, opts *SyncOptions) (SyncPlan, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	iss, err := jc.GetIssueWithOptionsContext(ctx, issueKey, &GetIssueOptions{Fields: []string{"attachment"}})
+	if err != nil {
+		return nil, err
+	}
+	remote := map[string]IssueFileList{}
+	for _, att := range iss.Files {
+		remote[att.Filename] = append(remote[att.Filename], att)
+	}
+	plan := SyncPlan{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		step := &SyncStep{Action: SyncUpload, Filename: entry.Name(), Local: filepath.Join(dir, entry.Name()), Remote: remote[entry.Name()]}
+		delete(remote, entry.Name())
+		plan = append(plan, step)
+		if len(step.Remote) == 0 {
+			continue
+		}
+		same, err := jc.findAttached(ctx, step.Local, step.Remote, opts.Checksum)
+		if err != nil {
+			return nil, err
+		}
+		if same != nil {
+			//Duplicates of the file are left alone
+			step.Action, step.Remote = SyncSkip, IssueFileList{same}
+		} else {
+			step.Action = SyncReplace
+		}
+	}
+	if opts.Delete {
+		for name, atts := range remote {
+			plan = append(plan, &SyncStep{Action: SyncDelete, Filename: name, Remote: atts})
+		}
+	}
+	sort.SliceStable(plan, func(i, j int) bool { return plan[i].Filename < plan[j].Filename })
+	return plan, nil
+}
+
+//Returns the attachment holding the same content as the local file, if any.
+func (jc *JiraClient) findAttached(ctx context.Context, local string, atts IssueFileList, checksum bool) (*IssueFile, error) {
+	fi, err := os.Stat(local)
+	if err != nil {
+		return nil, err
+	}
+	var sum []byte
+	for _, att := range atts {
+		if att.Size != fi.Size() {
+			continue
+		}
+		if !checksum {
+			return att, nil
+		}
+		if sum == nil {
+			if sum, err = fileChecksum(local); err != nil {
+				return nil, err
+			}
+		}
+		hash := sha256.New()
+		if err := jc.DownloadAttachmentContext(ctx, att, hash); err != nil {
+			return nil, err
+		}
+		if bytes.Equal(hash.Sum(nil), sum) {
+			return att, nil
+		}
+	}
+	return nil, nil
+}
+
+func fileChecksum(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+func (jc *JiraClient) syncUpload(ctx context.Context, issueKey string, step *SyncStep) error {
+	f, err := os.Open(step.Local)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if step.Uploaded, err = jc.UploadReaderContext(ctx, issueKey, step.Filename, f); err != nil {
+		return err
+	}
+	//Outdated versions are only deleted once the new one is attached
+	return jc.syncDelete(ctx, step.Remote)
+}
+
+func (jc *JiraClient) syncDelete(ctx context.Context, atts IssueFileList) error {
+	for _, att := range atts {
+		if err := jc.DelAttachmentByIdContext(ctx, att.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
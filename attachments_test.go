@@ -0,0 +1,170 @@
+package libgojira
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+//Fake Jira holding the attachments of ABC-1, recording uploads and deletions.
+type fakeAttachments struct {
+	t         *testing.T
+	files     map[string][2]string //Filename and content, by id
+	uploaded  []string
+	deleted   []string
+	downloads int
+	failPost  bool
+}
+
+func (fa *fakeAttachments) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/jira/rest/api/2/issue/ABC-1":
+		atts := []attachmentJSON{}
+		for id, f := range fa.files {
+			atts = append(atts, attachmentJSON{Id: id, Filename: f[0], Size: int64(len(f[1])), Content: fmt.Sprintf("http://%s/jira/secure/attachment/%s/%s", r.Host, id, f[0])})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "10001", "key": "ABC-1", "fields": map[string]interface{}{"attachment": atts}})
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/jira/secure/attachment/"):
+		fa.downloads++
+		id := strings.Split(r.URL.Path, "/")[4]
+		w.Write([]byte(fa.files[id][1]))
+	case r.Method == "POST" && r.URL.Path == "/jira/rest/api/2/issue/ABC-1/attachments":
+		if fa.failPost {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		f, h, err := r.FormFile("file")
+		if err != nil {
+			fa.t.Error(err)
+			return
+		}
+		b, _ := io.ReadAll(f)
+		id := fmt.Sprint(100 + len(fa.uploaded))
+		fa.uploaded = append(fa.uploaded, h.Filename)
+		fa.files[id] = [2]string{h.Filename, string(b)}
+		json.NewEncoder(w).Encode([]attachmentJSON{{Id: id, Filename: h.Filename, Size: int64(len(b))}})
+	case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, "/jira/rest/api/2/attachment/"):
+		id := strings.TrimPrefix(r.URL.Path, "/jira/rest/api/2/attachment/")
+		fa.deleted = append(fa.deleted, id)
+		delete(fa.files, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		fa.t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+//Returns a directory of local files and a fake Jira with attachments covering each kind of step.
+func newSyncFixture(t *testing.T) (string, *fakeAttachments, *JiraClient) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"new.txt": "new", "same.txt": "same", "changed.txt": "changed!", "samesize.txt": "abcd"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "subdir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	fa := &fakeAttachments{t: t, files: map[string][2]string{
+		"1": {"same.txt", "same"},
+		"2": {"changed.txt", "old"},
+		"3": {"samesize.txt", "wxyz"},
+		"4": {"gone.txt", "gone"},
+	}}
+	return dir, fa, newTestClient(t, fa.ServeHTTP)
+}
+
+func planActions(plan SyncPlan) string {
+	steps := []string{}
+	for _, step := range plan {
+		steps = append(steps, fmt.Sprintf("%s %s", step.Action, step.Filename))
+	}
+	return strings.Join(steps, ", ")
+}
+
+func TestSyncAttachments(t *testing.T) {
+	dir, fa, jc := newSyncFixture(t)
+	plan, err := jc.SyncAttachments("ABC-1", dir, &SyncOptions{Delete: true, Checksum: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := planActions(plan), "replace changed.txt, delete gone.txt, upload new.txt, skip same.txt, replace samesize.txt"; got != want {
+		t.Errorf("got plan %s, want %s", got, want)
+	}
+	sort.Strings(fa.uploaded)
+	sort.Strings(fa.deleted)
+	if got := strings.Join(fa.uploaded, ","); got != "changed.txt,new.txt,samesize.txt" {
+		t.Errorf("uploaded %s", got)
+	}
+	if got := strings.Join(fa.deleted, ","); got != "2,3,4" {
+		t.Errorf("deleted attachments %s, want 2,3,4", got)
+	}
+	for _, step := range plan {
+		if (step.Action == SyncUpload || step.Action == SyncReplace) && step.Uploaded == nil {
+			t.Errorf("%s: no uploaded attachment", step.Filename)
+		}
+	}
+	//The issue now mirrors the directory
+	plan, err = jc.SyncAttachments("ABC-1", dir, &SyncOptions{Delete: true, Checksum: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := planActions(plan), "skip changed.txt, skip new.txt, skip same.txt, skip samesize.txt"; got != want {
+		t.Errorf("got plan %s on the second run, want %s", got, want)
+	}
+}
+
+func TestSyncAttachmentsBySize(t *testing.T) {
+	dir, fa, jc := newSyncFixture(t)
+	plan, err := jc.SyncAttachments("ABC-1", dir, &SyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	//Without Delete, gone.txt stays; without Checksum, samesize.txt looks attached
+	if got, want := planActions(plan), "replace changed.txt, upload new.txt, skip same.txt, skip samesize.txt"; got != want {
+		t.Errorf("got plan %s, want %s", got, want)
+	}
+	if fa.downloads != 0 {
+		t.Errorf("downloaded %d attachments without Checksum", fa.downloads)
+	}
+	if got := strings.Join(fa.deleted, ","); got != "2" {
+		t.Errorf("deleted attachments %s, want 2", got)
+	}
+}
+
+func TestSyncAttachmentsDryRun(t *testing.T) {
+	dir, fa, jc := newSyncFixture(t)
+	plan, err := jc.SyncAttachments("ABC-1", dir, &SyncOptions{DryRun: true, Delete: true, Checksum: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := planActions(plan), "replace changed.txt, delete gone.txt, upload new.txt, skip same.txt, replace samesize.txt"; got != want {
+		t.Errorf("got plan %s, want %s", got, want)
+	}
+	if len(fa.uploaded) != 0 || len(fa.deleted) != 0 {
+		t.Errorf("dry run uploaded %v and deleted %v", fa.uploaded, fa.deleted)
+	}
+}
+
+func TestSyncAttachmentsFailedUpload(t *testing.T) {
+	dir, fa, jc := newSyncFixture(t)
+	fa.failPost = true
+	plan, err := jc.SyncAttachments("ABC-1", dir, &SyncOptions{})
+	if err == nil {
+		t.Fatal("no error")
+	}
+	if len(fa.deleted) != 0 {
+		t.Errorf("deleted attachments %v although their replacement failed", fa.deleted)
+	}
+	for _, step := range plan {
+		if step.Action != SyncSkip && step.Err == nil {
+			t.Errorf("%s %s: no error", step.Action, step.Filename)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package libgojira
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//How an instance converts days and weeks to hours in time tracking durations.
+type TimeTrackingConfig struct {
+	HoursPerDay float64 `json:"workingHoursPerDay"`
+	DaysPerWeek float64 `json:"workingDaysPerWeek"`
+}
+
+//Jira's own defaults, 8 hours a day and 5 days a week.
+var DefaultTimeTrackingConfig = TimeTrackingConfig{HoursPerDay: 8, DaysPerWeek: 5}
+
+//Fetches the time tracking configuration of the instance.
+func (jc *JiraClient) GetTimeTrackingConfig() (*TimeTrackingConfig, error) {
+	return jc.GetTimeTrackingConfigContext(context.Background())
+}
+
+func (jc *JiraClient) GetTimeTrackingConfigContext(ctx context.Context) (*TimeTrackingConfig, error) {
+	var conf struct {
+		TimeTrackingEnabled       bool               `json:"timeTrackingEnabled"`
+		TimeTrackingConfiguration TimeTrackingConfig `json:"timeTrackingConfiguration"`
+	}
+	if err := jc.getJSON(ctx, jc.apiUrl("configuration"), &conf); err != nil {
+		return nil, err
+	}
+	if !conf.TimeTrackingEnabled {
+		return nil, &JiraClientError{"Time tracking is disabled on this instance"}
+	}
+	return &conf.TimeTrackingConfiguration, nil
+}
+
+func (c *TimeTrackingConfig) orDefault() *TimeTrackingConfig {
+	if c == nil {
+		return &DefaultTimeTrackingConfig
+	}
+	return c
+}
+
+func (c *TimeTrackingConfig) units() []struct {
+	suffix string
+	length time.Duration
+} {
+	day := time.Duration(c.HoursPerDay * float64(time.Hour))
+	return []struct {
+		suffix string
+		length time.Duration
+	}{
+		{"w", time.Duration(c.DaysPerWeek * float64(day))},
+		{"d", day},
+		{"h", time.Hour},
+		{"m", time.Minute},
+	}
+}
+
+var jiraDurationUnit = regexp.MustCompile(`([wdhm])`)
+var jiraDurationPart = regexp.MustCompile(`^(\d+(?:\.\d+)?)([wdhm]?)$`)
+
+//Parses a duration in Jira syntax, such as "1w 2d 4h 30m". A number without unit is in minutes.
+func (c *TimeTrackingConfig) ParseDuration(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimSpace(strings.TrimPrefix(s, "-"))
+	//Allow the parts to be stuck together, as in 1w2d
+	parts := strings.Fields(jiraDurationUnit.ReplaceAllString(s, "$1 "))
+	if len(parts) == 0 {
+		return 0, fmt.Errorf("bad duration %q", s)
+	}
+	units := map[string]time.Duration{"": time.Minute}
+	for _, u := range c.units() {
+		units[u.suffix] = u.length
+	}
+	var d time.Duration
+	for _, part := range parts {
+		m := jiraDurationPart.FindStringSubmatch(part)
+		if m == nil {
+			return 0, fmt.Errorf("bad duration %q", s)
+		}
+		n, _ := strconv.ParseFloat(m[1], 64)
+		d += time.Duration(math.Round(n * float64(units[m[2]])))
+	}
+	if negative {
+		d = -d
+	}
+	return d, nil
+}
+
+//Formats a duration in Jira syntax, such as "1w 2d 4h 30m", rounded to the minute.
+func (c *TimeTrackingConfig) FormatDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	d = d.Round(time.Minute)
+	parts := []string{}
+	for _, u := range c.units() {
+		if u.length <= 0 {
+			continue
+		}
+		if n := d / u.length; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", n, u.suffix))
+			d -= n * u.length
+		}
+	}
+	if len(parts) == 0 {
+		return "0m"
+	}
+	return sign + strings.Join(parts, " ")
+}
+
+//Parses a duration in Jira syntax with DefaultTimeTrackingConfig.
+func ParseJiraDuration(s string) (time.Duration, error) {
+	return DefaultTimeTrackingConfig.ParseDuration(s)
+}
+
+//Formats a duration in Jira syntax with DefaultTimeTrackingConfig.
+func FormatJiraDuration(d time.Duration) string {
+	return DefaultTimeTrackingConfig.FormatDuration(d)
+}
+
+//Sets the original and remaining estimates of an issue, given in Jira syntax such as "2d 4h".
+//Jira converts days and weeks with its own configuration. An empty estimate is left unchanged.
+func (jc *JiraClient) SetEstimates(issueKey string, original, remaining string) error {
+	return jc.SetEstimatesContext(context.Background(), issueKey, original, remaining)
+}
+
+func (jc *JiraClient) SetEstimatesContext(ctx context.Context, issueKey string, original, remaining string) error {
+	timetracking := map[string]string{}
+	for name, estimate := range map[string]string{"originalEstimate": original, "remainingEstimate": remaining} {
+		if estimate == "" {
+			continue
+		}
+		if _, err := ParseJiraDuration(estimate); err != nil {
+			return err
+		}
+		timetracking[name] = estimate
+	}
+	if len(timetracking) == 0 {
+		return nil
+	}
+	if err := jc.sendJSON(ctx, "PUT", jc.apiUrl("issue/%s", issueKey), msi{"fields": msi{"timetracking": timetracking}}, nil); err != nil {
+		return err
+	}
+	jc.log().Infof("Estimates of %s updated!", issueKey)
+	return nil
+}
+
+//Sets the original and remaining estimates of an issue, rounded to the minute.
+//A negative estimate is left unchanged.
+func (jc *JiraClient) SetEstimateDurations(issueKey string, original, remaining time.Duration) error {
+	return jc.SetEstimateDurationsContext(context.Background(), issueKey, original, remaining)
+}
+
+func (jc *JiraClient) SetEstimateDurationsContext(ctx context.Context, issueKey string, original, remaining time.Duration) error {
+	//Minutes don't depend on the instance's configuration
+	minutes := func(d time.Duration) string {
+		if d < 0 {
+			return ""
+		}
+		return fmt.Sprintf("%dm", d.Round(time.Minute)/time.Minute)
+	}
+	return jc.SetEstimatesContext(ctx, issueKey, minutes(original), minutes(remaining))
+}
@@ -0,0 +1,86 @@
+package libgojira
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var shortDays = &TimeTrackingConfig{HoursPerDay: 7.5, DaysPerWeek: 5}
+
+func TestParseDuration(t *testing.T) {
+	for _, tc := range []struct {
+		conf *TimeTrackingConfig
+		in   string
+		want time.Duration
+	}{
+		{&DefaultTimeTrackingConfig, "1w2d", 56 * time.Hour},
+		{&DefaultTimeTrackingConfig, "1w 2d 4h 30m", 60*time.Hour + 30*time.Minute},
+		{&DefaultTimeTrackingConfig, "1.5h", 90 * time.Minute},
+		{&DefaultTimeTrackingConfig, "45", 45 * time.Minute},
+		{&DefaultTimeTrackingConfig, " 2H ", 2 * time.Hour},
+		{&DefaultTimeTrackingConfig, "-3m", -3 * time.Minute},
+		{&DefaultTimeTrackingConfig, "-1d 2h", -10 * time.Hour},
+		{shortDays, "1d", 7*time.Hour + 30*time.Minute},
+		{shortDays, "1w2d", 52*time.Hour + 30*time.Minute},
+		{shortDays, "0.5d", 3*time.Hour + 45*time.Minute},
+	} {
+		got, err := tc.conf.ParseDuration(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("%v.ParseDuration(%q) = %v, %v, want %v", *tc.conf, tc.in, got, err, tc.want)
+		}
+	}
+	for _, in := range []string{"", "-", "3x", "1hh", "h", "1.h", "1 d2"} {
+		if d, err := ParseJiraDuration(in); err == nil {
+			t.Errorf("ParseJiraDuration(%q) = %v, want an error", in, d)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for _, tc := range []struct {
+		conf *TimeTrackingConfig
+		in   time.Duration
+		want string
+	}{
+		{&DefaultTimeTrackingConfig, 0, "0m"},
+		{&DefaultTimeTrackingConfig, 56 * time.Hour, "1w 2d"},
+		{&DefaultTimeTrackingConfig, 90 * time.Minute, "1h 30m"},
+		{&DefaultTimeTrackingConfig, 89*time.Minute + 31*time.Second, "1h 30m"},
+		{&DefaultTimeTrackingConfig, -10 * time.Hour, "-1d 2h"},
+		{shortDays, 7*time.Hour + 30*time.Minute, "1d"},
+		{shortDays, 40 * time.Hour, "1w 2h 30m"},
+		{shortDays, -8 * time.Hour, "-1d 30m"},
+	} {
+		if got := tc.conf.FormatDuration(tc.in); got != tc.want {
+			t.Errorf("%v.FormatDuration(%v) = %q, want %q", *tc.conf, tc.in, got, tc.want)
+		}
+		if d, err := tc.conf.ParseDuration(tc.want); err != nil || d != tc.in.Round(time.Minute) {
+			t.Errorf("%v.ParseDuration(%q) = %v, %v, want %v", *tc.conf, tc.want, d, err, tc.in.Round(time.Minute))
+		}
+	}
+}
+
+func TestFormattersUseConfig(t *testing.T) {
+	tl := TimeLog{Key: "ABC-1", Date: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Seconds: 8 * 3600, LogId: "1", Author: "bob"}
+	if got := tl.FormatDuration(shortDays); got != "1d 30m" {
+		t.Errorf("FormatDuration = %q, want %q", got, "1d 30m")
+	}
+	if got := tl.PrettySeconds(); got != "1d" {
+		t.Errorf("PrettySeconds = %q, want %q", got, "1d")
+	}
+	tlm := TimeLogMap{tl.Date: {tl}}
+	if got := tlm.SprintWith(shortDays); !strings.Contains(got, "1d 30m (# 1 by bob)") {
+		t.Errorf("SprintWith doesn't use the config:\n%s", got)
+	}
+	issue := &Issue{Key: "ABC-1", OriginalEstimate: 16 * 3600, TimeSpent: 8 * 3600, TimeLog: tlm}
+	got := issue.PrettySprintWith(shortDays)
+	for _, want := range []string{"Original time estimate: 2d 1h", "Time spent: 1d 30m", "1d 30m (# 1 by bob)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("PrettySprintWith doesn't contain %q:\n%s", want, got)
+		}
+	}
+	if got := issue.PrettySprint(); !strings.Contains(got, "Original time estimate: 2d") {
+		t.Errorf("PrettySprint doesn't use the default config:\n%s", got)
+	}
+}
@@ -0,0 +1,97 @@
+package libgojira
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+//Sentinel errors an *APIError can be matched against with errors.Is.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+)
+
+//Error returned when Jira answers a request with an error status.
+type APIError struct {
+	StatusCode    int
+	Method        string
+	Url           string
+	ErrorMessages []string          //Jira's errorMessages
+	Errors        map[string]string //Jira's per-field errors, keyed by field name
+	Body          string            //Raw response body
+}
+
+func (e *APIError) Error() string {
+	msgs := append([]string{}, e.ErrorMessages...)
+	fields := make([]string, 0, len(e.Errors))
+	for k := range e.Errors {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+	for _, k := range fields {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", k, e.Errors[k]))
+	}
+	if len(msgs) == 0 && e.Body != "" {
+		msgs = append(msgs, e.Body)
+	}
+	s := fmt.Sprintf("%s %s: %d %s", e.Method, e.Url, e.StatusCode, http.StatusText(e.StatusCode))
+	if len(msgs) > 0 {
+		s += ": " + strings.Join(msgs, "; ")
+	}
+	return s
+}
+
+//Matches the error against the sentinel corresponding to its status code.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	}
+	return false
+}
+
+//Builds an *APIError from a failed response, consuming its body.
+func newAPIError(resp *http.Response) *APIError {
+	apierr := &APIError{StatusCode: resp.StatusCode}
+	if resp.Request != nil {
+		apierr.Method = resp.Request.Method
+		apierr.Url = resp.Request.URL.String()
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	apierr.Body = string(b)
+	var payload struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}
+	if json.Unmarshal(b, &payload) == nil {
+		apierr.ErrorMessages = payload.ErrorMessages
+		apierr.Errors = payload.Errors
+	}
+	return apierr
+}
+
+//Returns an *APIError if the response doesn't have a success status, consuming its body.
+//The body of a successful response is left to the caller, see decodeResponse.
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= 300 {
+		return newAPIError(resp)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package libgojira
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+type EstimateReportOptions struct {
+	//Flags issues whose spent and remaining time exceed their original estimate by more than
+	//this fraction, e.g. 0.2 for 20%.
+	Threshold float64
+	//Converts durations to days and weeks in WriteText, DefaultTimeTrackingConfig when nil
+	TimeTracking *TimeTrackingConfig
+}
+
+//Estimates of an issue, in seconds. Those of an issue with subtasks include the subtasks'.
+type IssueEstimate struct {
+	Key       string  `json:"key"`
+	Summary   string  `json:"summary"`
+	Type      string  `json:"type"`
+	Assignee  string  `json:"assignee"`
+	Parent    string  `json:"parent,omitempty"`
+	Original  float64 `json:"original"`
+	Remaining float64 `json:"remaining"`
+	Spent     float64 `json:"spent"`
+	Ratio     float64 `json:"ratio"`   //(Spent + Remaining) / Original, 0 when there's no original estimate
+	Overrun   bool    `json:"overrun"` //Ratio exceeds 1 + Threshold
+}
+
+//Estimates summed over a group of issues, in seconds.
+type EstimateAccuracy struct {
+	Name        string  `json:"name"`
+	Estimated   int     `json:"estimated"`   //Issues with an original estimate
+	Unestimated int     `json:"unestimated"` //Issues with time spent but no original estimate
+	Original    float64 `json:"original"`
+	Remaining   float64 `json:"remaining"`
+	Spent       float64 `json:"spent"`
+	Ratio       float64 `json:"ratio"` //(Spent + Remaining) / Original over the estimated issues
+	//Mean over the estimated issues of min(ratio, 1/ratio): 1 when every estimate was right,
+	//closer to 0 as estimates are further off in either direction.
+	Accuracy float64 `json:"accuracy"`
+}
+
+//Estimated vs. actual time of a set of issues and their subtasks.
+//Groups count each issue's own time only, excluding that of its subtasks, so nothing is counted twice.
+type EstimateReport struct {
+	Threshold    float64            `json:"threshold"`
+	Issues       []IssueEstimate    `json:"issues"`
+	ByAssignee   []EstimateAccuracy `json:"byAssignee"`
+	ByType       []EstimateAccuracy `json:"byType"`
+	Total        EstimateAccuracy   `json:"total"`
+	timeTracking *TimeTrackingConfig
+}
+
+//Reports on the issues and their subtasks, whether fetched with their parent or found among the issues.
+func NewEstimateReport(issues []*Issue, opts *EstimateReportOptions) *EstimateReport {
+	r := &EstimateReport{Threshold: opts.Threshold, Issues: []IssueEstimate{}, timeTracking: opts.TimeTracking}
+	byAssignee := map[string]*estimateSum{}
+	byType := map[string]*estimateSum{}
+	total := &estimateSum{}
+	//Parents' estimates include those of their subtasks
+	subtasks := map[string]map[string]*Issue{}
+	addSubtask := func(parent string, st *Issue) {
+		if _, ok := subtasks[parent]; !ok {
+			subtasks[parent] = map[string]*Issue{}
+		}
+		subtasks[parent][st.Key] = st
+	}
+	for _, issue := range issues {
+		if issue == nil {
+			continue
+		}
+		for _, st := range issue.SubTasks {
+			if st != nil {
+				addSubtask(issue.Key, st)
+			}
+		}
+		if issue.Parent != "" {
+			addSubtask(issue.Parent, issue)
+		}
+	}
+	seen := map[string]bool{}
+	var add func(issue *Issue)
+	add = func(issue *Issue) {
+		if issue == nil || seen[issue.Key] {
+			return
+		}
+		seen[issue.Key] = true
+		r.Issues = append(r.Issues, r.issueEstimate(issue))
+		own := IssueEstimate{Original: issue.OriginalEstimate, Remaining: issue.RemainingEstimate, Spent: issue.TimeSpent}
+		for _, st := range subtasks[issue.Key] {
+			own.Original -= st.OriginalEstimate
+			own.Remaining -= st.RemainingEstimate
+			own.Spent -= st.TimeSpent
+		}
+		own.Original, own.Remaining, own.Spent = math.Max(own.Original, 0), math.Max(own.Remaining, 0), math.Max(own.Spent, 0)
+		assignee := issue.Assignee
+		if assignee == "" {
+			assignee = "Unassigned"
+		}
+		for _, sum := range []*estimateSum{group(byAssignee, assignee), group(byType, issue.Type), total} {
+			sum.add(&own)
+		}
+		for _, st := range issue.SubTasks {
+			add(st)
+		}
+	}
+	for _, issue := range issues {
+		add(issue)
+	}
+	r.ByAssignee = accuracies(byAssignee)
+	r.ByType = accuracies(byType)
+	r.Total = total.accuracy("Total")
+	return r
+}
+
+func (r *EstimateReport) issueEstimate(issue *Issue) IssueEstimate {
+	ie := IssueEstimate{
+		Key:       issue.Key,
+		Summary:   issue.Summary,
+		Type:      issue.Type,
+		Assignee:  issue.Assignee,
+		Parent:    issue.Parent,
+		Original:  issue.OriginalEstimate,
+		Remaining: issue.RemainingEstimate,
+		Spent:     issue.TimeSpent,
+	}
+	if ie.Original > 0 {
+		ie.Ratio = (ie.Spent + ie.Remaining) / ie.Original
+		ie.Overrun = ie.Ratio > 1+r.Threshold
+	}
+	return ie
+}
+
+//Issues whose spent and remaining time exceed their original estimate by more than the threshold
+func (r *EstimateReport) Overruns() []IssueEstimate {
+	result := []IssueEstimate{}
+	for _, ie := range r.Issues {
+		if ie.Overrun {
+			result = append(result, ie)
+		}
+	}
+	return result
+}
+
+type estimateSum struct {
+	EstimateAccuracy
+	accuracySum float64
+}
+
+func group(groups map[string]*estimateSum, name string) *estimateSum {
+	if _, ok := groups[name]; !ok {
+		groups[name] = &estimateSum{}
+	}
+	return groups[name]
+}
+
+func (s *estimateSum) add(own *IssueEstimate) {
+	if own.Original <= 0 {
+		if own.Spent > 0 {
+			s.Unestimated++
+		}
+		return
+	}
+	s.Estimated++
+	s.Original += own.Original
+	s.Remaining += own.Remaining
+	s.Spent += own.Spent
+	ratio := (own.Spent + own.Remaining) / own.Original
+	if ratio > 0 {
+		s.accuracySum += math.Min(ratio, 1/ratio)
+	}
+}
+
+func (s *estimateSum) accuracy(name string) EstimateAccuracy {
+	a := s.EstimateAccuracy
+	a.Name = name
+	if a.Estimated > 0 {
+		a.Ratio = (a.Spent + a.Remaining) / a.Original
+		a.Accuracy = s.accuracySum / float64(a.Estimated)
+	}
+	return a
+}
+
+func accuracies(groups map[string]*estimateSum) []EstimateAccuracy {
+	result := make([]EstimateAccuracy, 0, len(groups))
+	for name, sum := range groups {
+		result = append(result, sum.accuracy(name))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+//Writes the issues, flagging overruns with a !, followed by the accuracy per assignee and per type.
+func (r *EstimateReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "\tIssue\tType\tAssignee\tOriginal\tSpent\tRemaining\tRatio\n")
+	for _, ie := range r.Issues {
+		flag := ""
+		if ie.Overrun {
+			flag = "!"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", flag, ie.Key, ie.Type, ie.Assignee,
+			r.formatSeconds(ie.Original), r.formatSeconds(ie.Spent), r.formatSeconds(ie.Remaining), ratio(ie.Ratio))
+	}
+	for _, section := range []struct {
+		title  string
+		groups []EstimateAccuracy
+	}{{"Assignee", r.ByAssignee}, {"Type", r.ByType}, {"", []EstimateAccuracy{r.Total}}} {
+		fmt.Fprintf(tw, "\n\t%s\tEstimated\tUnestimated\tOriginal\tSpent\tRemaining\tRatio\tAccuracy\n", section.title)
+		for _, a := range section.groups {
+			fmt.Fprintf(tw, "\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n", a.Name, a.Estimated, a.Unestimated,
+				r.formatSeconds(a.Original), r.formatSeconds(a.Spent), r.formatSeconds(a.Remaining), ratio(a.Ratio), ratio(a.Accuracy))
+		}
+	}
+	return tw.Flush()
+}
+
+func (r *EstimateReport) formatSeconds(f float64) string {
+	return r.timeTracking.orDefault().FormatDuration(time.Duration(f) * time.Second)
+}
+
+func ratio(f float64) string {
+	if f == 0 {
+		return "N/A"
+	}
+	return fmt.Sprintf("%.2f", f)
+}
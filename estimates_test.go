@@ -0,0 +1,56 @@
+package libgojira
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEstimateReportCountsSubtasksOnce(t *testing.T) {
+	subtask := &Issue{Key: "ABC-2", Type: "Sub-task", Parent: "ABC-1", OriginalEstimate: 3600, TimeSpent: 1800}
+	for _, tc := range []struct {
+		name   string
+		issues []*Issue
+	}{
+		//Searched without IncludeSubtasks, the parent's estimates still include the subtask's
+		{"subtask searched", []*Issue{{Key: "ABC-1", Type: "Story", OriginalEstimate: 7200, TimeSpent: 5400}, subtask}},
+		{"subtask fetched", []*Issue{{Key: "ABC-1", Type: "Story", OriginalEstimate: 7200, TimeSpent: 5400, SubTasks: []*Issue{subtask}}}},
+		{"subtask fetched and searched", []*Issue{{Key: "ABC-1", Type: "Story", OriginalEstimate: 7200, TimeSpent: 5400, SubTasks: []*Issue{subtask}}, subtask}},
+	} {
+		r := NewEstimateReport(tc.issues, &EstimateReportOptions{})
+		if r.Total.Original != 7200 || r.Total.Spent != 5400 || r.Total.Estimated != 2 {
+			t.Errorf("%s: got total %+v, want 7200s estimated over 2 issues, 5400s spent", tc.name, r.Total)
+		}
+		if len(r.Issues) != 2 || r.Issues[0].Original != 7200 || r.Issues[1].Original != 3600 {
+			t.Errorf("%s: unexpected issues %+v", tc.name, r.Issues)
+		}
+		for _, a := range r.ByType {
+			if a.Original != 3600 {
+				t.Errorf("%s: got %s original %v, want 3600", tc.name, a.Name, a.Original)
+			}
+		}
+	}
+}
+
+func TestEstimateReportWriteText(t *testing.T) {
+	r := NewEstimateReport([]*Issue{{Key: "ABC-1", Type: "Story", Assignee: "bob", OriginalEstimate: 9 * 3600, TimeSpent: 5400}}, &EstimateReportOptions{})
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "1d 1h") || !strings.Contains(out, "1h 30m") {
+		t.Errorf("durations not in Jira syntax:\n%s", out)
+	}
+}
+
+func TestEstimateReportTimeTracking(t *testing.T) {
+	issues := []*Issue{{Key: "ABC-1", Type: "Story", Assignee: "bob", OriginalEstimate: 9 * 3600, TimeSpent: 5400}}
+	r := NewEstimateReport(issues, &EstimateReportOptions{TimeTracking: &TimeTrackingConfig{HoursPerDay: 7.5, DaysPerWeek: 5}})
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "1d 1h 30m") {
+		t.Errorf("durations not converted with 7.5h days:\n%s", out)
+	}
+}
@@ -0,0 +1,408 @@
+package libgojira
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+type GraphOptions struct {
+	Depth     int      //Relations followed away from the roots, unbounded when negative; 0 only relates the roots
+	LinkTypes []string //Names of the link types whose outward description, such as "blocks", puts the issue before the other, {"Blocks"} when empty
+	//Converts remaining time to days and weeks in the graph's labels, DefaultTimeTrackingConfig when nil
+	TimeTracking *TimeTrackingConfig
+}
+
+//Kind of relation between two issues of a graph
+const (
+	EdgeBlocks  = "blocks"
+	EdgeSubtask = "subtask"
+)
+
+//Dependency between two issues: From must be done before To.
+//A blocking issue comes before the issue it blocks, a subtask before its parent.
+type GraphEdge struct {
+	From string
+	To   string
+	Kind string
+}
+
+//Dependencies among issues, crawled from their links and subtasks.
+type IssueGraph struct {
+	Nodes        map[string]*Issue
+	Edges        []GraphEdge
+	TimeTracking *TimeTrackingConfig //Converts remaining time to days and weeks in labels, DefaultTimeTrackingConfig when nil
+}
+
+var graphFields = []string{"summary", "status", "issuetype", "assignee", "parent", "subtasks", "issuelinks",
+	"timeoriginalestimate", "timeestimate", "timespent", "aggregatetimeoriginalestimate", "aggregatetimeestimate", "aggregatetimespent"}
+
+//Builds the graph of the issues related to the roots, up to opts.Depth relations away.
+func (jc *JiraClient) IssueGraph(roots []string, opts *GraphOptions) (*IssueGraph, error) {
+	return jc.IssueGraphContext(context.Background(), roots, opts)
+}
+
+func (jc *JiraClient) IssueGraphContext(ctx context.Context, roots []string, opts *GraphOptions) (*IssueGraph, error) {
+	linkTypes := map[string]bool{}
+	for _, lt := range opts.LinkTypes {
+		linkTypes[strings.ToLower(lt)] = true
+	}
+	if len(linkTypes) == 0 {
+		linkTypes["blocks"] = true
+	}
+	g := &IssueGraph{Nodes: map[string]*Issue{}, Edges: []GraphEdge{}, TimeTracking: opts.TimeTracking}
+	edges := map[GraphEdge]bool{}
+	depth := map[string]int{}
+	queue := []string{}
+	for _, key := range roots {
+		if _, ok := depth[key]; !ok {
+			depth[key] = 0
+			queue = append(queue, key)
+		}
+	}
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		issue, subtasks, err := jc.graphIssue(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		g.Nodes[key] = issue
+		related := []GraphEdge{}
+		if issue.Parent != "" {
+			related = append(related, GraphEdge{key, issue.Parent, EdgeSubtask})
+		}
+		for _, st := range subtasks {
+			related = append(related, GraphEdge{st, key, EdgeSubtask})
+		}
+		for _, link := range issue.Links {
+			if !linkTypes[strings.ToLower(link.Type.Name)] {
+				continue
+			}
+			if link.Inward {
+				related = append(related, GraphEdge{link.IssueKey, key, EdgeBlocks})
+			} else {
+				related = append(related, GraphEdge{key, link.IssueKey, EdgeBlocks})
+			}
+		}
+		for _, e := range related {
+			edges[e] = true
+			other := e.From
+			if other == key {
+				other = e.To
+			}
+			if _, ok := depth[other]; !ok && (opts.Depth < 0 || depth[key] < opts.Depth) {
+				depth[other] = depth[key] + 1
+				queue = append(queue, other)
+			}
+		}
+	}
+	for e := range edges {
+		//Relations of the issues at the edge of the crawl lead outside of the graph
+		if g.Nodes[e.From] != nil && g.Nodes[e.To] != nil {
+			g.Edges = append(g.Edges, e)
+		}
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Kind < b.Kind
+	})
+	return g, nil
+}
+
+//Builds the graph of the issues related to those matching a JQL query, up to opts.Depth relations away.
+func (jc *JiraClient) IssueGraphForQuery(jql string, opts *GraphOptions) (*IssueGraph, error) {
+	return jc.IssueGraphForQueryContext(context.Background(), jql, opts)
+}
+
+func (jc *JiraClient) IssueGraphForQueryContext(ctx context.Context, jql string, opts *GraphOptions) (*IssueGraph, error) {
+	issues, err := jc.SearchContext(ctx, &SearchOptions{JQL: jql, Fields: []string{"summary"}})
+	if err != nil {
+		return nil, err
+	}
+	roots := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		roots = append(roots, issue.Key)
+	}
+	return jc.IssueGraphContext(ctx, roots, opts)
+}
+
+//Fetches the fields of an issue the graph needs, along with the keys of its subtasks,
+//which are crawled as nodes of their own.
+func (jc *JiraClient) graphIssue(ctx context.Context, key string) (*Issue, []string, error) {
+	var ij issueJSON
+	params := (&GetIssueOptions{Fields: graphFields}).params()
+	if err := jc.getJSON(ctx, jc.apiUrl("issue/%s?%s", key, params.Encode()), &ij); err != nil {
+		return nil, nil, err
+	}
+	issue, err := issueFromJSON(&ij)
+	if err != nil {
+		return nil, nil, err
+	}
+	subtasks := make([]string, 0, len(ij.Fields.Subtasks))
+	for _, st := range ij.Fields.Subtasks {
+		subtasks = append(subtasks, st.Key)
+	}
+	return issue, subtasks, nil
+}
+
+//Node keys, sorted
+func (g *IssueGraph) keys() []string {
+	keys := make([]string, 0, len(g.Nodes))
+	for k := range g.Nodes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (g *IssueGraph) successors() map[string][]string {
+	succ := map[string][]string{}
+	for _, e := range g.Edges {
+		succ[e.From] = append(succ[e.From], e.To)
+	}
+	return succ
+}
+
+//Returns the groups of issues depending on each other in a circle, each sorted, found with Tarjan's algorithm.
+func (g *IssueGraph) Cycles() [][]string {
+	succ := g.successors()
+	index, low := map[string]int{}, map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+	cycles := [][]string{}
+	var visit func(key string)
+	visit = func(key string) {
+		index[key], low[key] = len(index), len(index)
+		stack = append(stack, key)
+		onStack[key] = true
+		selfLoop := false
+		for _, next := range succ[key] {
+			if next == key {
+				selfLoop = true
+			}
+			if _, seen := index[next]; !seen {
+				visit(next)
+				if low[next] < low[key] {
+					low[key] = low[next]
+				}
+			} else if onStack[next] && index[next] < low[key] {
+				low[key] = index[next]
+			}
+		}
+		if low[key] != index[key] {
+			return
+		}
+		component := []string{}
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == key {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoop {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+	for _, key := range g.keys() {
+		if _, seen := index[key]; !seen {
+			visit(key)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+//Returns the issues in an order respecting their dependencies, ties broken by key.
+//Fails when the graph has cycles.
+func (g *IssueGraph) TopologicalOrder() ([]string, error) {
+	if cycles := g.Cycles(); len(cycles) > 0 {
+		desc := make([]string, 0, len(cycles))
+		for _, c := range cycles {
+			desc = append(desc, strings.Join(c, ", "))
+		}
+		return nil, &JiraClientError{fmt.Sprintf("Dependency cycles between %s", strings.Join(desc, "; "))}
+	}
+	succ := g.successors()
+	indegree := map[string]int{}
+	for _, e := range g.Edges {
+		indegree[e.To]++
+	}
+	ready := []string{}
+	for _, key := range g.keys() {
+		if indegree[key] == 0 {
+			ready = append(ready, key)
+		}
+	}
+	order := make([]string, 0, len(g.Nodes))
+	for len(ready) > 0 {
+		key := ready[0]
+		ready = ready[1:]
+		order = append(order, key)
+		for _, next := range succ[key] {
+			if indegree[next]--; indegree[next] == 0 {
+				ready = append(ready, next)
+				sort.Strings(ready)
+			}
+		}
+	}
+	return order, nil
+}
+
+//Remaining time of the issue itself, in seconds. The estimates of a parent include those of its subtasks,
+//which are accounted for on their own nodes.
+func (g *IssueGraph) Weight(key string) float64 {
+	issue := g.Nodes[key]
+	if issue == nil {
+		return 0
+	}
+	w := issue.RemainingEstimate
+	for _, e := range g.Edges {
+		if e.Kind == EdgeSubtask && e.To == key && g.Nodes[e.From] != nil {
+			w -= g.Nodes[e.From].RemainingEstimate
+		}
+	}
+	return math.Max(w, 0)
+}
+
+//Returns the chain of dependencies with the most remaining time, and that time in seconds.
+//Fails when the graph has cycles.
+func (g *IssueGraph) CriticalPath() ([]string, float64, error) {
+	order, err := g.TopologicalOrder()
+	if err != nil {
+		return nil, 0, err
+	}
+	finish := map[string]float64{}
+	prev := map[string]string{}
+	for _, key := range order {
+		finish[key] += g.Weight(key)
+	}
+	//Predecessors are finished in order, so each node's finish time is final when reached
+	succ := g.successors()
+	for _, key := range order {
+		for _, next := range succ[key] {
+			if f := finish[key] + g.Weight(next); f > finish[next] {
+				finish[next], prev[next] = f, key
+			}
+		}
+	}
+	end, total := "", -1.0
+	for _, key := range order {
+		if finish[key] > total {
+			end, total = key, finish[key]
+		}
+	}
+	if end == "" {
+		return []string{}, 0, nil
+	}
+	path := []string{end}
+	for key := end; prev[key] != ""; key = prev[key] {
+		path = append([]string{prev[key]}, path...)
+	}
+	return path, total, nil
+}
+
+func (g *IssueGraph) label(key string) string {
+	issue := g.Nodes[key]
+	label := fmt.Sprintf("%s: %s", key, issue.Summary)
+	if issue.Status != "" {
+		label += fmt.Sprintf(" [%s]", issue.Status)
+	}
+	if w := g.Weight(key); w > 0 {
+		label += " " + g.TimeTracking.orDefault().FormatDuration(time.Duration(w)*time.Second)
+	}
+	return label
+}
+
+func (g *IssueGraph) criticalEdges() map[GraphEdge]bool {
+	critical := map[GraphEdge]bool{}
+	path, _, err := g.CriticalPath()
+	if err != nil {
+		return critical
+	}
+	for i := 1; i < len(path); i++ {
+		critical[GraphEdge{From: path[i-1], To: path[i]}] = true
+	}
+	return critical
+}
+
+//Writes the graph in Graphviz's DOT language. Subtask relations are dashed,
+//the critical path is bold and issues in cycles are red.
+func (g *IssueGraph) WriteDOT(w io.Writer) error {
+	inCycle := map[string]bool{}
+	for _, c := range g.Cycles() {
+		for _, key := range c {
+			inCycle[key] = true
+		}
+	}
+	critical := g.criticalEdges()
+	var b strings.Builder
+	b.WriteString("digraph issues {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for _, key := range g.keys() {
+		attrs := ""
+		if inCycle[key] {
+			attrs = ", color=red"
+		}
+		fmt.Fprintf(&b, "\t%s [label=%s%s];\n", dotQuote(key), dotQuote(g.label(key)), attrs)
+	}
+	for _, e := range g.Edges {
+		attrs := []string{"label=" + dotQuote(e.Kind)}
+		if e.Kind == EdgeSubtask {
+			attrs = append(attrs, "style=dashed")
+		}
+		if critical[GraphEdge{From: e.From, To: e.To}] {
+			attrs = append(attrs, "penwidth=3")
+		}
+		fmt.Fprintf(&b, "\t%s -> %s [%s];\n", dotQuote(e.From), dotQuote(e.To), strings.Join(attrs, ", "))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+var mermaidUnsafe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+//Writes the graph as a Mermaid flowchart. Subtask relations are dotted
+//and the critical path is thick.
+func (g *IssueGraph) WriteMermaid(w io.Writer) error {
+	id := func(key string) string {
+		return mermaidUnsafe.ReplaceAllString(key, "_")
+	}
+	critical := g.criticalEdges()
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, key := range g.keys() {
+		fmt.Fprintf(&b, "\t%s[\"%s\"]\n", id(key), strings.Replace(g.label(key), `"`, "#quot;", -1))
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		switch {
+		case critical[GraphEdge{From: e.From, To: e.To}]:
+			arrow = "==>"
+		case e.Kind == EdgeSubtask:
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "\t%s %s|%s| %s\n", id(e.From), arrow, e.Kind, id(e.To))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
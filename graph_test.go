@@ -0,0 +1,196 @@
+package libgojira
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+//Returns a client talking to a fake Jira serving the issues given as their fields, counting the requests per issue.
+func newGraphClient(t *testing.T, issues map[string]string) (*JiraClient, map[string]int) {
+	requests := map[string]int{}
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/jira/rest/api/2/issue/")
+		requests[key]++
+		fields, ok := issues[key]
+		if !ok {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"key":%q,"fields":%s}`, key, fields)
+	})
+	return jc, requests
+}
+
+const blocksType = `{"id":"10000","name":"Blocks","inward":"is blocked by","outward":"blocks"}`
+
+//A story blocked by ABC-1, split in two subtasks and blocking ABC-5
+var storyIssues = map[string]string{
+	"ABC-1": `{"summary":"Design","aggregatetimeestimate":3600,"issuelinks":[{"id":"1","type":` + blocksType + `,"outwardIssue":{"key":"ABC-2"}}]}`,
+	"ABC-2": `{"summary":"Build","aggregatetimeestimate":18000,"subtasks":[{"key":"ABC-3"},{"key":"ABC-4"}],"issuelinks":[` +
+		`{"id":"1","type":` + blocksType + `,"inwardIssue":{"key":"ABC-1"}},{"id":"2","type":` + blocksType + `,"outwardIssue":{"key":"ABC-5"}}]}`,
+	"ABC-3": `{"summary":"Backend","parent":{"key":"ABC-2"},"issuetype":{"name":"Sub-task","subtask":true},"timeestimate":7200}`,
+	"ABC-4": `{"summary":"Frontend","parent":{"key":"ABC-2"},"issuetype":{"name":"Sub-task","subtask":true},"timeestimate":3600}`,
+	"ABC-5": `{"summary":"Ship","aggregatetimeestimate":1800,"issuelinks":[{"id":"2","type":` + blocksType + `,"inwardIssue":{"key":"ABC-2"}}]}`,
+}
+
+func TestIssueGraphFetchesEachIssueOnce(t *testing.T) {
+	jc, requests := newGraphClient(t, storyIssues)
+	jc.options.IncludeSubtasks = true
+	g, err := jc.IssueGraph([]string{"ABC-2"}, &GraphOptions{Depth: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Nodes) != 5 {
+		t.Errorf("got %d nodes, want 5", len(g.Nodes))
+	}
+	for key, n := range requests {
+		if n != 1 {
+			t.Errorf("%s fetched %d times", key, n)
+		}
+	}
+}
+
+func TestGraphLabelsTimeTracking(t *testing.T) {
+	g := &IssueGraph{Nodes: map[string]*Issue{"ABC-1": {Key: "ABC-1", Summary: "Fix it", RemainingEstimate: 9 * 3600}}, Edges: []GraphEdge{}}
+	for conf, want := range map[*TimeTrackingConfig]string{nil: "1d 1h", {HoursPerDay: 7.5, DaysPerWeek: 5}: "1d 1h 30m"} {
+		g.TimeTracking = conf
+		var buf bytes.Buffer
+		if err := g.WriteDOT(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), `"ABC-1: Fix it `+want+`"`) {
+			t.Errorf("want %s remaining in:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestIssueGraphDepth(t *testing.T) {
+	for _, tc := range []struct {
+		depth int
+		nodes string
+		edges string
+	}{
+		{0, "ABC-1", ""},
+		{1, "ABC-1,ABC-2", "ABC-1 blocks ABC-2"},
+		{2, "ABC-1,ABC-2,ABC-3,ABC-4,ABC-5", "ABC-1 blocks ABC-2,ABC-2 blocks ABC-5,ABC-3 subtask ABC-2,ABC-4 subtask ABC-2"},
+		{-1, "ABC-1,ABC-2,ABC-3,ABC-4,ABC-5", "ABC-1 blocks ABC-2,ABC-2 blocks ABC-5,ABC-3 subtask ABC-2,ABC-4 subtask ABC-2"},
+	} {
+		jc, requests := newGraphClient(t, storyIssues)
+		g, err := jc.IssueGraph([]string{"ABC-1"}, &GraphOptions{Depth: tc.depth})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(g.keys(), ","); got != tc.nodes {
+			t.Errorf("depth %d: got nodes %s, want %s", tc.depth, got, tc.nodes)
+		}
+		edges := []string{}
+		for _, e := range g.Edges {
+			edges = append(edges, e.From+" "+e.Kind+" "+e.To)
+		}
+		if got := strings.Join(edges, ","); got != tc.edges {
+			t.Errorf("depth %d: got edges %s, want %s", tc.depth, got, tc.edges)
+		}
+		if len(requests) != len(g.Nodes) {
+			t.Errorf("depth %d: fetched %v", tc.depth, requests)
+		}
+	}
+}
+
+//Returns a graph of issues without estimates, given edges such as "A>B", meaning A blocks B.
+func testGraph(nodes string, edges ...string) *IssueGraph {
+	g := &IssueGraph{Nodes: map[string]*Issue{}, Edges: []GraphEdge{}}
+	for _, key := range strings.Split(nodes, ",") {
+		g.Nodes[key] = &Issue{Key: key}
+	}
+	for _, e := range edges {
+		ends := strings.Split(e, ">")
+		g.Edges = append(g.Edges, GraphEdge{ends[0], ends[1], EdgeBlocks})
+	}
+	return g
+}
+
+func TestCycles(t *testing.T) {
+	for _, tc := range []struct {
+		g    *IssueGraph
+		want string
+	}{
+		{testGraph("A,B,C", "A>B", "B>C", "A>C"), "[]"},
+		{testGraph("A,B", "A>B", "B>A"), "[[A B]]"},
+		{testGraph("A,B", "A>A", "A>B"), "[[A]]"},
+		{testGraph("A,B,C,D,E,F", "A>B", "B>C", "C>A", "C>D", "D>E", "E>D", "F>F"), "[[A B C] [D E] [F]]"},
+		{testGraph("A,B,C,D", "D>C", "C>B", "B>D", "B>A", "A>A"), "[[A] [B C D]]"},
+	} {
+		if got := fmt.Sprint(tc.g.Cycles()); got != tc.want {
+			t.Errorf("%v: got cycles %s, want %s", tc.g.Edges, got, tc.want)
+		}
+	}
+}
+
+func TestTopologicalOrder(t *testing.T) {
+	for _, tc := range []struct {
+		g    *IssueGraph
+		want string
+	}{
+		{testGraph("A,B,C,D", "A>C", "B>C"), "A,B,C,D"},
+		//Ties are broken by key, among the issues ready at each step
+		{testGraph("A,B,C,D", "D>A", "C>B"), "C,B,D,A"},
+		{testGraph("ABC-10,ABC-2,ABC-9", "ABC-9>ABC-10"), "ABC-2,ABC-9,ABC-10"},
+		{testGraph("A,B,C,D,E", "E>D", "D>C", "C>B", "B>A"), "E,D,C,B,A"},
+	} {
+		order, err := tc.g.TopologicalOrder()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(order, ","); got != tc.want {
+			t.Errorf("%v: got order %s, want %s", tc.g.Edges, got, tc.want)
+		}
+	}
+	if _, err := testGraph("A,B,C", "A>B", "B>A", "B>C").TopologicalOrder(); err == nil || !strings.Contains(err.Error(), "A, B") {
+		t.Errorf("got %v for a cycle between A and B", err)
+	}
+}
+
+func TestCriticalPath(t *testing.T) {
+	jc, _ := newGraphClient(t, storyIssues)
+	g, err := jc.IssueGraph([]string{"ABC-1"}, &GraphOptions{Depth: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	//ABC-2's aggregate estimate includes its subtasks', accounted for on their own nodes
+	for key, want := range map[string]float64{"ABC-1": 3600, "ABC-2": 7200, "ABC-3": 7200, "ABC-4": 3600, "ABC-5": 1800} {
+		if w := g.Weight(key); w != want {
+			t.Errorf("Weight(%s) = %v, want %v", key, w, want)
+		}
+	}
+	path, total, err := g.CriticalPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(path, ",") != "ABC-3,ABC-2,ABC-5" || total != 16200 {
+		t.Errorf("got critical path %v of %vs, want ABC-3,ABC-2,ABC-5 of 16200s", path, total)
+	}
+	var dot, mermaid bytes.Buffer
+	if err := g.WriteDOT(&dot); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(dot.String(), `"ABC-2" -> "ABC-5" [label="blocks", penwidth=3];`) ||
+		!strings.Contains(dot.String(), `"ABC-4" -> "ABC-2" [label="subtask", style=dashed];`) {
+		t.Errorf("unexpected DOT:\n%s", dot.String())
+	}
+	if err := g.WriteMermaid(&mermaid); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(mermaid.String(), "ABC_3 ==>|subtask| ABC_2") || !strings.Contains(mermaid.String(), "ABC_1 -->|blocks| ABC_2") {
+		t.Errorf("unexpected Mermaid:\n%s", mermaid.String())
+	}
+	if _, _, err := testGraph("A,B", "A>B", "B>A").CriticalPath(); err == nil {
+		t.Error("no error for a graph with cycles")
+	}
+	if path, total, err := (&IssueGraph{Nodes: map[string]*Issue{}}).CriticalPath(); err != nil || len(path) != 0 || total != 0 {
+		t.Errorf("got %v, %v, %v for an empty graph", path, total, err)
+	}
+}
@@ -0,0 +1,352 @@
+package libgojira
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Matches issue keys such as ABC-123 in free text.
+var DefaultKeyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9]+-[0-9]+\b`)
+
+//Time tracked in another tool, to be logged on a Jira issue.
+type ImportEntry struct {
+	Key       string //Issue the time goes to, looked up in Summary when empty
+	Summary   string
+	Started   time.Time
+	TimeSpent time.Duration
+	Comment   string
+	Source    string //Where the entry was read, for error reporting
+}
+
+//Names of the columns of a CSV export, as found in its header row. Columns left empty are ignored.
+//The time spent is read from Duration when set, or computed from Started and End.
+type CSVColumns struct {
+	Key        string
+	Summary    string
+	Started    string
+	End        string
+	Duration   string //Go duration such as 1h30m, or decimal hours such as 1.5
+	Comment    string
+	TimeFormat string         //Layout of Started and End, JIRA_TIME_FORMAT when empty
+	Location   *time.Location //Time zone of timestamps without an offset, local time when nil
+}
+
+//Reads the entries of a CSV export whose first row holds the column names.
+func ReadCSV(r io.Reader, cols *CSVColumns) ([]ImportEntry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{cols.Key, cols.Summary, cols.Started, cols.End, cols.Duration, cols.Comment} {
+		if _, ok := index[name]; name != "" && !ok {
+			return nil, fmt.Errorf("no column named %q", name)
+		}
+	}
+	if cols.Started == "" || (cols.Duration == "" && cols.End == "") {
+		return nil, &JiraClientError{"CSV columns must include a start and either a duration or an end"}
+	}
+	if cols.Key == "" && cols.Summary == "" {
+		return nil, &JiraClientError{"CSV columns must include a key or a summary"}
+	}
+	format := cols.TimeFormat
+	if format == "" {
+		format = JIRA_TIME_FORMAT
+	}
+	loc := cols.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	entries := []ImportEntry{}
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := index[name]; ok && name != "" && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		e := ImportEntry{Key: field(cols.Key), Summary: field(cols.Summary), Comment: field(cols.Comment), Source: fmt.Sprintf("line %d", line)}
+		if e.Started, err = time.ParseInLocation(format, field(cols.Started), loc); err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Source, err)
+		}
+		if cols.Duration != "" {
+			e.TimeSpent, err = parseImportDuration(field(cols.Duration))
+		} else {
+			var end time.Time
+			end, err = time.ParseInLocation(format, field(cols.End), loc)
+			e.TimeSpent = end.Sub(e.Started)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Source, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func parseImportDuration(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	h, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad duration %q", s)
+	}
+	return time.Duration(h * float64(time.Hour)), nil
+}
+
+//Reads the VEVENTs of an iCalendar file. The event's SUMMARY becomes the entry's summary
+//and its DESCRIPTION the worklog comment. Events lasting a whole day are ignored,
+//as are the components nested in events, such as VALARM.
+func ReadICal(r io.Reader) ([]ImportEntry, error) {
+	lines, err := unfoldICal(r)
+	if err != nil {
+		return nil, err
+	}
+	entries := []ImportEntry{}
+	var e *ImportEntry
+	var end time.Time
+	allDay := false
+	nested := 0 //Depth of the components opened inside the current event
+	for i, line := range lines {
+		name, params, value := parseICalLine(line)
+		switch {
+		case e == nil && name == "BEGIN" && value == "VEVENT":
+			e, end, allDay, nested = &ImportEntry{Source: fmt.Sprintf("event at line %d", i+1)}, time.Time{}, false, 0
+		case e == nil:
+		case name == "BEGIN":
+			nested++
+		case nested > 0:
+			if name == "END" {
+				nested--
+			}
+		case name == "END" && value == "VEVENT":
+			if e.TimeSpent == 0 && !end.IsZero() {
+				e.TimeSpent = end.Sub(e.Started)
+			}
+			if !allDay {
+				entries = append(entries, *e)
+			}
+			e = nil
+		case name == "UID":
+			e.Source = "event " + value
+		case name == "SUMMARY":
+			e.Summary = unescapeICal(value)
+		case name == "DESCRIPTION":
+			e.Comment = unescapeICal(value)
+		case name == "DTSTART":
+			allDay = params["VALUE"] == "DATE"
+			if e.Started, err = parseICalTime(value, params["TZID"]); err != nil {
+				return nil, fmt.Errorf("%s: %w", e.Source, err)
+			}
+		case name == "DTEND":
+			if end, err = parseICalTime(value, params["TZID"]); err != nil {
+				return nil, fmt.Errorf("%s: %w", e.Source, err)
+			}
+		case name == "DURATION":
+			if e.TimeSpent, err = parseICalDuration(value); err != nil {
+				return nil, fmt.Errorf("%s: %w", e.Source, err)
+			}
+		}
+	}
+	return entries, nil
+}
+
+//Joins the lines folded by RFC 5545, which continue on lines starting with a space or a tab.
+func unfoldICal(r io.Reader) ([]string, error) {
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+//Splits a content line such as DTSTART;TZID=Europe/Paris:20240102T090000.
+func parseICalLine(line string) (string, map[string]string, string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return strings.ToUpper(line), nil, ""
+	}
+	parts := strings.Split(line[:colon], ";")
+	params := map[string]string{}
+	for _, p := range parts[1:] {
+		if kv := strings.SplitN(p, "=", 2); len(kv) == 2 {
+			params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:]
+}
+
+func unescapeICal(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
+
+func parseICalTime(value, tzid string) (time.Time, error) {
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+	loc := time.Local
+	if tzid != "" {
+		var err error
+		if loc, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, err
+		}
+	}
+	if len(value) == len("20060102") {
+		return time.ParseInLocation("20060102", value, loc)
+	}
+	return time.ParseInLocation("20060102T150405", value, loc)
+}
+
+var icalDuration = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+//Parses a duration such as PT1H30M.
+func parseICalDuration(value string) (time.Duration, error) {
+	m := icalDuration.FindStringSubmatch(value)
+	if m == nil || value == "P" || value == "PT" {
+		return 0, fmt.Errorf("bad duration %q", value)
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if n, err := strconv.Atoi(m[i+2]); err == nil {
+			d += time.Duration(n) * unit
+		}
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+//What happened to an imported entry.
+type ImportStatus string
+
+const (
+	ImportAdded     ImportStatus = "added"
+	ImportWouldAdd  ImportStatus = "would add" //Dry run
+	ImportDuplicate ImportStatus = "duplicate" //Already logged in Jira, or earlier in the import
+	ImportSkipped   ImportStatus = "skipped"   //No issue key, or no time spent
+	ImportFailed    ImportStatus = "failed"
+)
+
+type ImportOptions struct {
+	KeyPattern *regexp.Regexp //Finds the issue key in the summary of entries without one, DefaultKeyPattern when nil
+	DryRun     bool           //Only report what would be logged
+}
+
+type ImportResult struct {
+	Entry   ImportEntry
+	Status  ImportStatus
+	TimeLog TimeLog //Worklog created, or the one duplicated
+	Err     error   //Why the entry was skipped or failed
+}
+
+//Logs the entries on their issues, skipping those already logged by the client's user:
+//a worklog started on the same minute for the same duration is considered a duplicate.
+//The user is matched against the worklogs' author login, account id or email, since Jira Cloud only sends the latter two.
+//Failures are reported per entry; the error is only set when ctx is done.
+func (jc *JiraClient) ImportWorkLogs(entries []ImportEntry, opts *ImportOptions) ([]ImportResult, error) {
+	return jc.ImportWorkLogsContext(context.Background(), entries, opts)
+}
+
+func (jc *JiraClient) ImportWorkLogsContext(ctx context.Context, entries []ImportEntry, opts *ImportOptions) ([]ImportResult, error) {
+	pattern := opts.KeyPattern
+	if pattern == nil {
+		pattern = DefaultKeyPattern
+	}
+	results := make([]ImportResult, len(entries))
+	since := map[string]time.Time{}
+	for i, e := range entries {
+		results[i].Entry = e
+		if e.Key == "" {
+			e.Key = pattern.FindString(e.Summary)
+			results[i].Entry.Key = e.Key
+		}
+		switch {
+		case e.Key == "":
+			results[i].Status, results[i].Err = ImportSkipped, fmt.Errorf("%s: no issue key", e.Source)
+		case e.TimeSpent < time.Minute:
+			results[i].Status, results[i].Err = ImportSkipped, fmt.Errorf("%s: less than a minute spent", e.Source)
+		default:
+			if s, ok := since[e.Key]; !ok || e.Started.Before(s) {
+				since[e.Key] = e.Started
+			}
+		}
+	}
+	existing := map[string][]TimeLog{}
+	fetchErrs := map[string]error{}
+	for key, s := range since {
+		logs, err := jc.GetWorkLogsContext(ctx, key, s.Truncate(time.Minute))
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		existing[key], fetchErrs[key] = logs, err
+	}
+	for i := range results {
+		r := &results[i]
+		if r.Status != "" {
+			continue
+		}
+		if err := fetchErrs[r.Entry.Key]; err != nil {
+			r.Status, r.Err = ImportFailed, err
+			continue
+		}
+		if dup, ok := findDuplicate(existing[r.Entry.Key], &r.Entry, jc.User); ok {
+			r.Status, r.TimeLog = ImportDuplicate, dup
+			continue
+		}
+		if opts.DryRun {
+			r.Status = ImportWouldAdd
+		} else {
+			tl, err := jc.AddWorkLogContext(ctx, r.Entry.Key, &WorkLogOptions{Started: r.Entry.Started, TimeSpent: r.Entry.TimeSpent, Comment: r.Entry.Comment})
+			if ctx.Err() != nil {
+				return results, ctx.Err()
+			}
+			if err != nil {
+				r.Status, r.Err = ImportFailed, err
+				continue
+			}
+			r.Status, r.TimeLog = ImportAdded, tl
+		}
+		//Later entries for the same slot are duplicates of this one
+		existing[r.Entry.Key] = append(existing[r.Entry.Key], TimeLog{Key: r.Entry.Key, Started: r.Entry.Started, Seconds: int(r.Entry.TimeSpent.Seconds()), Author: jc.User, Comment: r.Entry.Comment})
+	}
+	return results, nil
+}
+
+func findDuplicate(logs []TimeLog, e *ImportEntry, user string) (TimeLog, bool) {
+	for _, tl := range logs {
+		if user != "" && !tl.By(user) {
+			continue
+		}
+		if tl.Started.Truncate(time.Minute).Equal(e.Started.Truncate(time.Minute)) && tl.Seconds/60 == int(e.TimeSpent.Minutes()) {
+			return tl, true
+		}
+	}
+	return TimeLog{}, false
+}
@@ -0,0 +1,67 @@
+package libgojira
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadICalIgnoresAlarms(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:1",
+		"SUMMARY:Review ABC-12",
+		"DESCRIPTION:Code review",
+		"DTSTART:20240102T090000Z",
+		"DURATION:PT1H30M",
+		"BEGIN:VALARM",
+		"TRIGGER:-PT15M",
+		"ACTION:DISPLAY",
+		"DESCRIPTION:Reminder",
+		"DURATION:PT5M",
+		"END:VALARM",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	entries, err := ReadICal(strings.NewReader(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Comment != "Code review" || e.TimeSpent != 90*time.Minute || e.Summary != "Review ABC-12" || e.Source != "event 1" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
+
+func TestImportDuplicateOnCloud(t *testing.T) {
+	//Jira Cloud identifies authors by account id, and logs in with an email
+	worklogs := `{"startAt":0,"maxResults":100,"total":2,"worklogs":[
+		{"id":"1","started":"2024-01-02T09:00:00.000+0000","timeSpentSeconds":3600,"author":{"accountId":"5b10ac8d","emailAddress":"Someone.Else@example.com"}},
+		{"id":"2","started":"2024-01-02T10:00:00.000+0000","timeSpentSeconds":3600,"author":{"accountId":"5b10a2844c","emailAddress":"Me@example.com"}}]}`
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(worklogs))
+	})
+	jc.User = "me@example.com"
+	entries := []ImportEntry{
+		{Key: "ABC-1", Started: time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC), TimeSpent: time.Hour},
+		{Key: "ABC-1", Started: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC), TimeSpent: time.Hour},
+	}
+	results, err := jc.ImportWorkLogs(entries, &ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Status != ImportWouldAdd {
+		t.Errorf("someone else's worklog: got %s, want %s", results[0].Status, ImportWouldAdd)
+	}
+	if results[1].Status != ImportDuplicate || results[1].TimeLog.LogId != "2" {
+		t.Errorf("own worklog: got %s %+v, want %s", results[1].Status, results[1].TimeLog, ImportDuplicate)
+	}
+}
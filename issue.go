@@ -2,14 +2,15 @@ package libgojira
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os/exec"
 	"regexp"
 	"strings"
+	"time"
 )
 
 //Representation of a single issue
@@ -30,6 +31,36 @@ type Issue struct {
 	Updated           string
 	Points            string
 	SubTasks          []*Issue
+	Links             []*IssueLink
+
+	//Only populated when requested through the expand option
+	Names          map[string]string      //Display names of the fields, keyed by field id
+	RenderedFields map[string]interface{} //Fields rendered to HTML, keyed by field id
+	Transitions    []*Transition
+	Changelog      []*ChangeHistory
+}
+
+//Transition available from an issue's current status
+type Transition struct {
+	Id       string
+	Name     string
+	ToStatus string
+}
+
+//Set of field changes made at once on an issue
+type ChangeHistory struct {
+	Id      string
+	Author  string
+	Created time.Time
+	Items   []ChangeItem
+}
+
+type ChangeItem struct {
+	Field      string
+	From       string
+	FromString string
+	To         string
+	ToString   string
 }
 
 func (i *Issue) QRCodeBase64() string {
@@ -54,12 +85,16 @@ func (i *Issue) ETag() string {
 }
 
 func (i *Issue) TaskTransition(jc *JiraClient, transition string, extra_fields msi) error {
-	id, err := i.getTransitionId(transition, jc)
+	return i.TaskTransitionContext(context.Background(), jc, transition, extra_fields)
+}
+
+func (i *Issue) TaskTransitionContext(ctx context.Context, jc *JiraClient, transition string, extra_fields msi) error {
+	id, err := i.getTransitionId(ctx, transition, jc)
 	if err != nil {
 
 		return err
 	}
-	err = i.doTransitionWithFields(id, extra_fields, jc)
+	err = i.doTransitionWithFields(ctx, id, extra_fields, jc)
 	if err != nil {
 		return err
 	}
@@ -70,9 +105,21 @@ func (i *Issue) TaskTransition(jc *JiraClient, transition string, extra_fields m
 type CommentList []*Comment
 
 type Comment struct {
-	Id         string
-	Body       string
-	AuthorName string
+	Id               string
+	Body             string
+	AuthorName       string //Display name of the author
+	AuthorAccountId  string
+	AuthorEmail      string
+	UpdateAuthorName string //Display name of the last person who edited the comment
+	Created          time.Time
+	Updated          time.Time
+	Visibility       *Visibility //Restriction of the comment, nil when everyone can see it
+}
+
+//Restricts who can see a comment or worklog
+type Visibility struct {
+	Type  string `json:"type"`  //"role" or "group"
+	Value string `json:"value"` //Name of the role or group
 }
 
 func (cm *Comment) String() string {
@@ -89,14 +136,20 @@ func (cl CommentList) String() string {
 
 type IssueFileList []*IssueFile
 
+//Attachment of an issue
 type IssueFile struct {
-	name string
-	url  string
-	self string
+	Id       string
+	Filename string
+	Size     int64 //In bytes
+	MimeType string
+	Author   string
+	Created  time.Time
+	Content  string //Url of the content, see JiraClient.DownloadAttachment
+	Self     string
 }
 
 func (issf *IssueFile) String() string {
-	return fmt.Sprintf("%s : %s", issf.name, issf.url)
+	return fmt.Sprintf("%s : %s", issf.Filename, issf.Content)
 }
 
 func (ifl IssueFileList) String() string {
@@ -116,20 +169,27 @@ func (i *Issue) String() string {
 }
 
 func (i *Issue) Url() string {
-	return fmt.Sprintf("https://%s/browse/%s", Server, i.Key)
+	return fmt.Sprintf("%s/browse/%s", baseUrl(Server), i.Key)
 }
 
+//Server used to build browse urls, in the same form as Options.Server.
 var Server string
 
 func (i *Issue) PrettySprint() string {
+	return i.PrettySprintWith(nil)
+}
+
+//Like PrettySprint, converting durations to days and weeks with conf, DefaultTimeTrackingConfig when nil.
+func (i *Issue) PrettySprintWith(conf *TimeTrackingConfig) string {
+	conf = conf.orDefault()
 	sa := make([]string, 0)
 	sa = append(sa, fmt.Sprintln(i.String()))
 	sa = append(sa, fmt.Sprintln(fmt.Sprintf("Jira URL: %s", i.Url())))
 	sa = append(sa, fmt.Sprintln(fmt.Sprintf("Status: %s", i.Status)))
 	sa = append(sa, fmt.Sprintln(fmt.Sprintf("Assignee: %s", i.Assignee)))
-	sa = append(sa, fmt.Sprintln(fmt.Sprintf("Original time estimate: %s", PrettySeconds(int(i.OriginalEstimate)))))
-	sa = append(sa, fmt.Sprintln(fmt.Sprintf("Time spent: %s", PrettySeconds(int(i.TimeSpent)))))
-	sa = append(sa, fmt.Sprintln(fmt.Sprintf("Remaining time estimated: %s", PrettySeconds(int(i.RemainingEstimate)))))
+	sa = append(sa, fmt.Sprintln(fmt.Sprintf("Original time estimate: %s", conf.FormatDuration(time.Duration(i.OriginalEstimate)*time.Second))))
+	sa = append(sa, fmt.Sprintln(fmt.Sprintf("Time spent: %s", conf.FormatDuration(time.Duration(i.TimeSpent)*time.Second))))
+	sa = append(sa, fmt.Sprintln(fmt.Sprintf("Remaining time estimated: %s", conf.FormatDuration(time.Duration(i.RemainingEstimate)*time.Second))))
 	r, _ := regexp.Compile("[*]([^*]*)[*]")
 	splitdesc := strings.Split(i.Description, "\n")
 	for k, v := range splitdesc {
@@ -151,34 +211,38 @@ func (i *Issue) PrettySprint() string {
 	}
 
 	if len(i.TimeLog) > 0 {
-		sa = append(sa, fmt.Sprintln(fmt.Sprintf("Worklog: \n%v", i.TimeLog)))
+		sa = append(sa, fmt.Sprintln(fmt.Sprintf("Worklog: \n%s", i.TimeLog.SprintWith(conf))))
 	}
 
 	return strings.Join(sa, "\n")
 }
 
 func (i *Issue) Assign(author string, jc *JiraClient) error {
+	return i.AssignContext(context.Background(), author, jc)
+}
+
+func (i *Issue) AssignContext(ctx context.Context, author string, jc *JiraClient) error {
 	js, err := json.Marshal(map[string]interface{}{"name": author})
 	if err != nil {
 		return err
 	}
-	resp, err := jc.Put(fmt.Sprintf("https://%s/rest/api/2/issue/%s/assignee", jc.options.Server, i.Key), "application/json", bytes.NewBuffer(js))
+	resp, err := jc.PutContext(ctx, jc.apiUrl("issue/%s/assignee", i.Key), "application/json", bytes.NewBuffer(js))
 	if err != nil {
 		return err
 	}
-	if resp.StatusCode != 204 {
-		s, _ := ioutil.ReadAll(resp.Body)
-		return &IssueError{fmt.Sprintf("%d: %s", resp.StatusCode, string(s))}
-	}
-	return nil
+	return decodeResponse(resp, nil)
 }
 
 func (i *Issue) StartProgress(jc *JiraClient) error {
-	id, err := i.getTransitionId("start", jc)
+	return i.StartProgressContext(context.Background(), jc)
+}
+
+func (i *Issue) StartProgressContext(ctx context.Context, jc *JiraClient) error {
+	id, err := i.getTransitionId(ctx, "start", jc)
 	if err != nil {
 		return err
 	}
-	err = i.doTransition(id, jc)
+	err = i.doTransition(ctx, id, jc)
 	if err != nil {
 		return err
 	}
@@ -187,11 +251,15 @@ func (i *Issue) StartProgress(jc *JiraClient) error {
 }
 
 func (i *Issue) StopProgress(jc *JiraClient) error {
-	id, err := i.getTransitionId("stop", jc)
+	return i.StopProgressContext(context.Background(), jc)
+}
+
+func (i *Issue) StopProgressContext(ctx context.Context, jc *JiraClient) error {
+	id, err := i.getTransitionId(ctx, "stop", jc)
 	if err != nil {
 		return err
 	}
-	err = i.doTransition(id, jc)
+	err = i.doTransition(ctx, id, jc)
 	if err != nil {
 		return err
 	}
@@ -229,29 +297,19 @@ func (r Resolutions) String() string {
 }
 
 func (i *Issue) PossibleResolutions(jc *JiraClient) (Resolutions, error) {
-	resp, err := jc.Get(fmt.Sprintf("https://%s/rest/api/2/issue/%s/transitions?expand=transitions.fields", jc.Server, i.Key))
-	if err != nil {
-		return nil, err
-	}
-	obj, err := JsonToInterface(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	return i.PossibleResolutionsContext(context.Background(), jc)
+}
 
-	txs, err := jsonWalker("transitions", obj)
-	if err != nil {
+func (i *Issue) PossibleResolutionsContext(ctx context.Context, jc *JiraClient) (Resolutions, error) {
+	var txs transitionsJSON
+	if err := jc.getJSON(ctx, jc.apiUrl("issue/%s/transitions?expand=transitions.fields", i.Key), &txs); err != nil {
 		return nil, err
 	}
 	result := Resolutions{}
-	for _, tx := range txs.([]interface{}) {
-		name, _ := jsonWalker("name", tx)
-		if strings.Contains(name.(string), "Resolve") {
-			txRes, err := jsonWalker("fields/resolution/allowedValues", tx)
-			if err == nil {
-				for _, singleRes := range txRes.([]interface{}) {
-					n, _ := jsonWalker("name", singleRes)
-					result = append(result, strings.ToLower(n.(string)))
-				}
+	for _, tx := range txs.Transitions {
+		if strings.Contains(tx.Name, "Resolve") {
+			for _, singleRes := range tx.Fields["resolution"].AllowedValues {
+				result = append(result, strings.ToLower(singleRes.Name))
 			}
 		}
 	}
@@ -259,59 +317,47 @@ func (i *Issue) PossibleResolutions(jc *JiraClient) (Resolutions, error) {
 }
 
 func (i *Issue) ResolveIssue(jc *JiraClient, resolution string) error {
-	id, err := i.getTransitionId("resolve", jc)
+	return i.ResolveIssueContext(context.Background(), jc, resolution)
+}
+
+func (i *Issue) ResolveIssueContext(ctx context.Context, jc *JiraClient, resolution string) error {
+	id, err := i.getTransitionId(ctx, "resolve", jc)
 	if err != nil {
 		return err
 	}
-	err = i.doTransitionWithFields(id, map[string]interface{}{"resolution": map[string]interface{}{"name": capitalize(resolution)}}, jc)
+	err = i.doTransitionWithFields(ctx, id, map[string]interface{}{"resolution": map[string]interface{}{"name": capitalize(resolution)}}, jc)
 	if err != nil {
-		res, _ := i.PossibleResolutions(jc)
-		return newIssueError(fmt.Sprintf("Command failed. Possible resolution values include: \n%sOriginal Error: %s", res, err.Error()))
+		res, _ := i.PossibleResolutionsContext(ctx, jc)
+		return fmt.Errorf("Command failed. Possible resolution values include: \n%sOriginal Error: %w", res, err)
 	}
 	return nil
 }
 
-func (i *Issue) doTransitionWithFields(id string, fields interface{}, jc *JiraClient) error {
+func (i *Issue) doTransitionWithFields(ctx context.Context, id string, fields interface{}, jc *JiraClient) error {
 	putJs, err := json.Marshal(map[string]interface{}{"transition": map[string]interface{}{"id": id}, "fields": fields})
 	if err != nil {
 		return err
 	}
-	resp, err := jc.Post(fmt.Sprintf("https://%s/rest/api/2/issue/%s/transitions", jc.options.Server, i.Key), "application/json", bytes.NewBuffer(putJs))
-	if resp.StatusCode != 204 {
-		s, _ := ioutil.ReadAll(resp.Body)
-		return &IssueError{fmt.Sprintf("%d: %s", resp.StatusCode, string(s))}
+	resp, err := jc.PostContext(ctx, jc.apiUrl("issue/%s/transitions", i.Key), "application/json", bytes.NewBuffer(putJs))
+	if err != nil {
+		return err
 	}
-	return nil
+	return decodeResponse(resp, nil)
 
 }
 
-func (i *Issue) doTransition(id string, jc *JiraClient) error {
-	return i.doTransitionWithFields(id, nil, jc)
+func (i *Issue) doTransition(ctx context.Context, id string, jc *JiraClient) error {
+	return i.doTransitionWithFields(ctx, id, nil, jc)
 }
 
-func (i *Issue) getTransitionId(transition string, jc *JiraClient) (string, error) {
-	resp, err := jc.Get(fmt.Sprintf("https://%s/rest/api/2/issue/%s/transitions", jc.options.Server, i.Key))
-	if err != nil {
-		return "", err
-	}
-	if resp.StatusCode != 200 {
-		s, _ := ioutil.ReadAll(resp.Body)
-		return "", &IssueError{fmt.Sprintf("%d: %s", resp.StatusCode, string(s))}
-	}
-	js, err := JsonToInterface(resp.Body)
-	if err != nil {
+func (i *Issue) getTransitionId(ctx context.Context, transition string, jc *JiraClient) (string, error) {
+	var txs transitionsJSON
+	if err := jc.getJSON(ctx, jc.apiUrl("issue/%s/transitions", i.Key), &txs); err != nil {
 		return "", err
 	}
-	transitions, err := jsonWalker("transitions", js)
-	if err != nil {
-		return "", err
-	}
-	for _, v := range transitions.([]interface{}) {
-		name, _ := jsonWalker("name", v)
-		if n, ok := name.(string); ok && strings.Contains(strings.ToLower(n), transition) {
-			tid, _ := jsonWalker("id", v)
-			return tid.(string), nil
-
+	for _, tx := range txs.Transitions {
+		if strings.Contains(strings.ToLower(tx.Name), transition) {
+			return tx.Id, nil
 		}
 	}
 	return "", &IssueError{"Transition ID not found"}
@@ -1,21 +1,22 @@
 package libgojira
 
 import (
-	"bufio"
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"log"
 	"mime/multipart"
 	"net/http"
 	"net/http/cookiejar"
+	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/hoisie/mustache"
 	"thezombie.net/oauth1a"
@@ -28,11 +29,14 @@ type Options struct {
 	NoCheckSSL bool   `short:"n" long:"no-check-ssl" description:"Don't check ssl validity"`
 	UseStdIn   bool   `long:"stdin"`
 
-	Verbose  bool     `short:"v" long:"verbose" description:"Be verbose"`
+	Verbose  bool     `short:"v" long:"verbose" description:"Be verbose (trace requests to stderr)"`
 	Projects []string `short:"j" long:"project"`
 
-	Server          string `short:"s" long:"server" description:"Jira server (just the domain name)"`
+	Server          string `short:"s" long:"server" description:"Jira server (domain name, or base url such as http://localhost:8080/jira)"`
 	IncludeSubtasks bool   `short:"a" long:"subtasks" description:"When grabbing an issue, also grab its subtasks"`
+
+	RateLimit   float64 `long:"rate-limit" description:"Maximum number of requests per second sent to Jira"`
+	MaxInFlight int     `long:"max-in-flight" description:"Maximum number of concurrent requests sent to Jira"`
 }
 
 var options Options
@@ -49,6 +53,17 @@ type JiraClient struct {
 	options      Options
 	OAuthCfg     *oauth1a.UserConfig
 	OAuthService *oauth1a.Service
+	Logger       Logger         //Diagnostic output, discarded when nil
+	Retry        *RetryPolicy   //Retries of transient failures, disabled when nil
+	Limiter      *RateLimiter   //Rate and concurrency limit, unbounded when nil
+	Location     *time.Location //Time zone worklogs are bucketed by day in, local time when nil
+}
+
+func (jc *JiraClient) location() *time.Location {
+	if jc.Location == nil {
+		return time.Local
+	}
+	return jc.Location
 }
 
 func NewJiraClient(options Options) *JiraClient {
@@ -57,12 +72,18 @@ func NewJiraClient(options Options) *JiraClient {
 	}
 	//	options.Verbose = true
 
-	jar, err := cookiejar.New(nil)
-	if err != nil {
-		log.Println(err)
-	}
+	jar, _ := cookiejar.New(nil)
 	client := &http.Client{Transport: tr, Jar: jar}
-	return &JiraClient{client, options.User, options.Passwd, options.Server, options, nil, nil}
+	jc := &JiraClient{client: client, User: options.User, Passwd: options.Passwd, Server: options.Server, options: options}
+	retry := DefaultRetryPolicy
+	jc.Retry = &retry
+	if options.RateLimit > 0 || options.MaxInFlight > 0 {
+		jc.Limiter = NewRateLimiter(options.RateLimit, 1, options.MaxInFlight)
+	}
+	if options.Verbose {
+		jc.Logger = NewStdLogger(log.New(os.Stderr, "", log.LstdFlags), true)
+	}
+	return jc
 
 }
 
@@ -72,51 +93,57 @@ func (jc *JiraClient) GetClient() *http.Client {
 
 type msi map[string]interface{}
 
-type Link struct {
-	Issue string
+func (jc *JiraClient) AddComment(issueKey string, comment string) (err error) {
+	return jc.AddCommentContext(context.Background(), issueKey, comment)
+}
 
-	LinkReason    string
-	LinkedToIssue string
-	Comment       string
+func (jc *JiraClient) AddCommentContext(ctx context.Context, issueKey string, comment string) (err error) {
+	_, err = jc.AddCommentWithOptionsContext(ctx, issueKey, &CommentOptions{Body: comment})
+	return err
 }
 
-func (jc *JiraClient) Link(link *Link) error {
-	m := msi{"type": msi{"name": link.LinkReason}, "inwardIssue": msi{"key": link.Issue}, "outwardIssue": msi{"key": link.LinkedToIssue}}
-	if link.Comment != "" {
-		m["comment"] = msi{"body": link.Comment}
-	}
-	w := bytes.NewBuffer([]byte{})
-	enc := json.NewEncoder(w)
-	enc.Encode(m)
-	resp, err := jc.Post(fmt.Sprintf("%sLink", jc.issueUrl()), "application/json", w)
-	if err != nil {
-		return err
-	}
-	if resp.StatusCode >= 400 {
-		b, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf(string(b))
-	}
-	return nil
+//Content of a new comment
+type CommentOptions struct {
+	Body       string
+	Visibility *Visibility //Restricts the comment to a role or group when set
 }
 
-func (jc *JiraClient) AddComment(issueKey string, comment string) (err error) {
-	b, err := json.Marshal(map[string]interface{}{"body": comment})
-	if err != nil {
-		return err
+func (co *CommentOptions) payload() msi {
+	m := msi{"body": co.Body}
+	if co.Visibility != nil {
+		m["visibility"] = co.Visibility
 	}
-	url := fmt.Sprintf("%s/%s/comment", jc.issueUrl(), issueKey)
-	if jc.options.Verbose {
-		fmt.Println(url)
+	return m
+}
+
+//Posts a comment, returning it as created by Jira.
+func (jc *JiraClient) AddCommentWithOptions(issueKey string, opts *CommentOptions) (*Comment, error) {
+	return jc.AddCommentWithOptionsContext(context.Background(), issueKey, opts)
+}
+
+func (jc *JiraClient) AddCommentWithOptionsContext(ctx context.Context, issueKey string, opts *CommentOptions) (*Comment, error) {
+	var cm commentJSON
+	if err := jc.sendJSON(ctx, "POST", fmt.Sprintf("%s/%s/comment", jc.issueUrl(), issueKey), opts.payload(), &cm); err != nil {
+		return nil, err
 	}
-	r, err := jc.Post(url, "application/json", bytes.NewBuffer(b))
+	return commentFromJSON(&cm), nil
+}
 
+//Replaces the body of a comment, returning it as updated by Jira.
+func (jc *JiraClient) UpdateComment(issueKey string, comment_id string, body string) (*Comment, error) {
+	return jc.UpdateCommentContext(context.Background(), issueKey, comment_id, body)
+}
+
+func (jc *JiraClient) UpdateCommentContext(ctx context.Context, issueKey string, comment_id string, body string) (*Comment, error) {
+	cid, err := numOnly(comment_id)
 	if err != nil {
-		return jc.printRespErr(r, err)
+		return nil, &JiraClientError{"Bad comment id"}
 	}
-	if r.StatusCode >= 400 {
-		return jc.printRespErr(r, &JiraClientError{"Oops."})
+	var cm commentJSON
+	if err := jc.sendJSON(ctx, "PUT", fmt.Sprintf("%s/%s/comment/%s", jc.issueUrl(), issueKey, cid), msi{"body": body}, &cm); err != nil {
+		return nil, err
 	}
-	return err
+	return commentFromJSON(&cm), nil
 }
 
 var numregex *regexp.Regexp = regexp.MustCompile("[0-9]+")
@@ -130,391 +157,364 @@ func numOnly(s string) (string, error) {
 }
 
 func (jc *JiraClient) DelWorkLog(issueKey string, worklog_id string) (err error) {
-	return jc.delById("worklog", issueKey, worklog_id)
+	return jc.DelWorkLogContext(context.Background(), issueKey, worklog_id)
+}
+
+func (jc *JiraClient) DelWorkLogContext(ctx context.Context, issueKey string, worklog_id string) (err error) {
+	return jc.delById(ctx, "worklog", issueKey, worklog_id)
 }
 
 func (jc *JiraClient) DelComment(issueKey string, comment_id string) (err error) {
-	return jc.delById("comment", issueKey, comment_id)
+	return jc.DelCommentContext(context.Background(), issueKey, comment_id)
+}
+
+func (jc *JiraClient) DelCommentContext(ctx context.Context, issueKey string, comment_id string) (err error) {
+	return jc.delById(ctx, "comment", issueKey, comment_id)
 }
 
-func (jc *JiraClient) delById(issueobject, issuekey, id string) (err error) {
+func (jc *JiraClient) delById(ctx context.Context, issueobject, issuekey, id string) (err error) {
 	cid, err := numOnly(id)
 	if err != nil {
 		return &JiraClientError{fmt.Sprintf("Bad %s id", issueobject)}
 	}
-	r, err := jc.Delete(fmt.Sprintf("%s/%s/%s/%s", jc.issueUrl(), issuekey, issueobject, cid), "", nil)
+	r, err := jc.DeleteContext(ctx, fmt.Sprintf("%s/%s/%s/%s", jc.issueUrl(), issuekey, issueobject, cid), "", nil)
 	if err != nil {
-		return jc.printRespErr(r, err)
+		return err
 	}
-	return err
+	return decodeResponse(r, nil)
 }
 
-func (jc *JiraClient) GetComments(issueKey string) (err error) {
-
-	return &JiraClientError{"Not implemented"}
+//Fetches every comment of an issue, unlike the embedded comment field which Jira truncates.
+func (jc *JiraClient) GetComments(issueKey string) (CommentList, error) {
+	return jc.GetCommentsContext(context.Background(), issueKey)
 }
 
-func (jc *JiraClient) printRespErr(res *http.Response, err error) error {
-	if jc.options.Verbose {
-		fmt.Println("Status code: ", res.StatusCode)
+func (jc *JiraClient) GetCommentsContext(ctx context.Context, issueKey string) (CommentList, error) {
+	result := CommentList{}
+	for {
+		var page commentPageJSON
+		if err := jc.getJSON(ctx, jc.apiUrl("issue/%s/comment?startAt=%d", issueKey, len(result)), &page); err != nil {
+			return nil, err
+		}
+		result = append(result, commentsFromJSON(page.Comments)...)
+		if len(page.Comments) == 0 || len(result) >= page.Total {
+			return result, nil
+		}
 	}
-	s, _ := ioutil.ReadAll(res.Body)
-	fmt.Println(string(s))
-	return err
 }
 
+//Deletes the attachment of an issue with the given filename.
+//Fails without deleting anything when several attachments have that name, see DelAttachmentById.
 func (jc *JiraClient) DelAttachment(issueKey string, att_name string) (err error) {
-	iss, err := jc.GetIssue(issueKey)
+	return jc.DelAttachmentContext(context.Background(), issueKey, att_name)
+}
+
+func (jc *JiraClient) DelAttachmentContext(ctx context.Context, issueKey string, att_name string) (err error) {
+	iss, err := jc.GetIssueContext(ctx, issueKey)
 	if err != nil {
 		return err
 	}
-
+	matches := IssueFileList{}
 	for _, att := range iss.Files {
-		if att.name == att_name {
-			res, err := jc.Delete(att.self, "", nil)
-			if res.StatusCode == 404 {
-				return &JiraClientError{"Not found"}
-			}
-			if res.StatusCode == 403 {
-				return &JiraClientError{"Unauthorized"}
-			}
-			if jc.options.Verbose {
-				fmt.Println(res.StatusCode)
-				sb, _ := ioutil.ReadAll(res.Body)
-				fmt.Println(string(sb))
-			}
-
-			if err != nil {
-				return err
-			}
-			log.Println("File removed from issue!")
-			return nil
+		if att.Filename == att_name {
+			matches = append(matches, att)
 		}
 	}
-	return &JiraClientError{"File not found"}
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("file %s on %s: %w", att_name, issueKey, ErrNotFound)
+	case 1:
+		if err = jc.DelAttachmentByIdContext(ctx, matches[0].Id); err != nil {
+			return err
+		}
+		jc.log().Infof("%s removed from %s", att_name, issueKey)
+		return nil
+	}
+	ids := make([]string, 0, len(matches))
+	for _, att := range matches {
+		ids = append(ids, att.Id)
+	}
+	return &JiraClientError{fmt.Sprintf("%d files named %s on %s (ids %s), delete one by id", len(matches), att_name, issueKey, strings.Join(ids, ", "))}
+}
 
+//Deletes an attachment given its id.
+func (jc *JiraClient) DelAttachmentById(attachment_id string) error {
+	return jc.DelAttachmentByIdContext(context.Background(), attachment_id)
 }
 
-func (jc *JiraClient) Upload(issueKey string, file string) (err error) {
-	// Prepare a form that you will submit to that URL.
-	var b bytes.Buffer
-	w := multipart.NewWriter(&b)
-	// Add your image file
-	f, err := os.Open(file)
+func (jc *JiraClient) DelAttachmentByIdContext(ctx context.Context, attachment_id string) error {
+	aid, err := numOnly(attachment_id)
 	if err != nil {
-		return
+		return &JiraClientError{"Bad attachment id"}
 	}
-	fi, err := os.Lstat(file)
-	fw, err := w.CreateFormFile("file", fi.Name())
+	r, err := jc.DeleteContext(ctx, jc.apiUrl("attachment/%s", aid), "", nil)
 	if err != nil {
-		return
-	}
-	if _, err = io.Copy(fw, f); err != nil {
-		return
+		return err
 	}
-	// Don't forget to close the multipart writer.
-	// If you don't close it, your request will be missing the terminating boundary.
-	w.Close()
-
-	// Now that you have a form, you can submit it to your handler.
+	return decodeResponse(r, nil)
+}
 
-	res, err := jc.Post(fmt.Sprintf("https://%s/rest/api/2/issue/%s/attachments", jc.Server, issueKey), w.FormDataContentType(), &b)
+func (jc *JiraClient) Upload(issueKey string, file string) (err error) {
+	return jc.UploadContext(context.Background(), issueKey, file)
+}
 
+func (jc *JiraClient) UploadContext(ctx context.Context, issueKey string, file string) (err error) {
+	f, err := os.Open(file)
 	if err != nil {
-		s, _ := ioutil.ReadAll(res.Body)
-		fmt.Println(string(s))
-		return err
+		return
 	}
-	fmt.Println("File uploaded!")
-	return nil
+	defer f.Close()
+	_, err = jc.UploadReaderContext(ctx, issueKey, filepath.Base(file), f)
+	return
 }
 
-//Represents search options to Jira
-type SearchOptions struct {
-	Projects      []string //Limit search to a specific project
-	CurrentSprint bool     //Limit search to stories in current sprint
-	Open          bool     //Limit search to open issues
-	Issue         string   //Limit search to a single issue
-	JQL           string   //Pure JQL query, has precedence over any other option
-	Type          []string
-	NotType       []string
-	Status        []string
-	NotStatus     []string
-}
-
-func (ja *JiraClient) Search(searchoptions *SearchOptions) ([]*Issue, error) {
-	var jqlstr string
-	if searchoptions.JQL == "" {
-		jql := make([]string, 0)
-		if searchoptions.CurrentSprint {
-			jql = append(jql, "sprint+in+openSprints()")
-		}
-		if searchoptions.Open {
-			jql = append(jql, "status+=+'open'")
-		}
-		if searchoptions.Issue != "" {
-			searchoptions.Issue = strings.Replace(searchoptions.Issue, " ", "+", -1)
-			jql = append(jql, fmt.Sprintf("issue+=+'%s'+or+parent+=+'%s'", searchoptions.Issue, searchoptions.Issue))
-		}
-		if len(searchoptions.Projects) > 0 {
-			searchoptions.Projects = searchoptions.Projects //strings.Replace(strings.searchoptions.Project, " ", "+", -1)
-			jql = append(jql, fmt.Sprintf("project+in+('%s')", strings.Replace(strings.Join(searchoptions.Projects, "','"), " ", "+", -1)))
-		}
-		if len(searchoptions.Type) > 0 {
-			jql = append(jql, strings.Replace(fmt.Sprintf("type+in+('%s')", strings.Join(searchoptions.Type, "','")), " ", "+", -1))
-		}
-		if len(searchoptions.NotType) > 0 {
-			jql = append(jql, strings.Replace(fmt.Sprintf("type+not+in+(%s)", strings.Join(searchoptions.NotType, ",")), " ", "+", -1))
-		}
-		if len(searchoptions.Status) > 0 {
-			jql = append(jql, strings.Replace(fmt.Sprintf("status+in+('%s')", strings.Join(searchoptions.Status, "','")), " ", "+", -1))
+//Attaches the content read from r to an issue under the given filename.
+//The content is streamed as it is read, so the upload isn't retried on transient failures.
+func (jc *JiraClient) UploadReader(issueKey string, filename string, r io.Reader) (*IssueFile, error) {
+	return jc.UploadReaderContext(context.Background(), issueKey, filename, r)
+}
+
+func (jc *JiraClient) UploadReaderContext(ctx context.Context, issueKey string, filename string, r io.Reader) (*IssueFile, error) {
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	w := multipart.NewWriter(pw)
+	go func() {
+		fw, err := w.CreateFormFile("file", filename)
+		if err == nil {
+			_, err = io.Copy(fw, r)
 		}
-		if len(searchoptions.NotStatus) > 0 {
-			jql = append(jql, strings.Replace(fmt.Sprintf("status+not+in+('%s')", strings.Join(searchoptions.NotStatus, "','")), " ", "+", -1))
+		if err == nil {
+			// Without closing the multipart writer, the request would be missing the terminating boundary.
+			err = w.Close()
 		}
-
-		jqlstr = strings.Join(jql, "+AND+") + "+order+by+rank"
-	} else {
-		jqlstr = strings.Replace(searchoptions.JQL, " ", "+", -1)
+		pw.CloseWithError(err)
+	}()
+	var attachments []attachmentJSON
+	res, err := jc.PostContext(ctx, jc.apiUrl("issue/%s/attachments", issueKey), w.FormDataContentType(), pr)
+	if err != nil {
+		return nil, err
 	}
-	url := fmt.Sprintf("https://%s/rest/api/2/search?jql=%s&fields=*all", ja.Server, jqlstr)
-	if ja.options.Verbose {
-		fmt.Println(url)
+	if err = decodeResponse(res, &attachments); err != nil {
+		return nil, err
 	}
-	i := 0
-	result := []*Issue{}
-	for {
-		if options.Verbose {
-			fmt.Println(url + fmt.Sprintf("&startAt=%d", i))
-		}
-		resp, err := ja.Get(url + fmt.Sprintf("&startAt=%d", i))
-		if err != nil {
-			if resp != nil {
-				fmt.Println(resp.StatusCode)
-				b, _ := ioutil.ReadAll(resp.Body)
-				fmt.Println(string(b))
-			}
-			return nil, err
-		}
-		if resp.StatusCode >= 300 {
-			fmt.Println(resp.StatusCode)
-			b, _ := ioutil.ReadAll(resp.Body)
-			fmt.Println(string(b))
-
-			return nil, &JiraClientError{resp.Status}
-		}
-
-		obj, err := JsonToInterface(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		issues, _ := jsonWalker("issues", obj)
-		issuesSlice, ok := issues.([]interface{})
-
-		if !ok {
-			issuesSlice = []interface{}{}
-		}
+	if len(attachments) == 0 {
+		return nil, &JiraClientError{"Jira didn't return the uploaded attachment"}
+	}
+	jc.log().Infof("%s uploaded to %s", filename, issueKey)
+	return fileFromJSON(&attachments[0]), nil
+}
 
-		for _, v := range issuesSlice {
-			iss, err := ja.NewIssueFromIface(v)
-			if err == nil {
-				result = append(result, iss)
-			}
-			if err != nil {
-				fmt.Println(err)
-			}
+//Writes the content of an attachment to w.
+func (jc *JiraClient) DownloadAttachment(att *IssueFile, w io.Writer) error {
+	return jc.DownloadAttachmentContext(context.Background(), att, w)
+}
 
-		}
-		i = len(result)
-		if i >= (int(obj.(map[string]interface{})["total"].(float64)) - 1) {
-			break
-		}
+func (jc *JiraClient) DownloadAttachmentContext(ctx context.Context, att *IssueFile, w io.Writer) error {
+	if att.Content == "" {
+		return &JiraClientError{"Attachment has no content url"}
 	}
-	return result, nil
+	res, err := jc.GetContext(ctx, att.Content)
+	if err != nil {
+		return err
+	}
+	if err = checkStatus(res); err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, err = io.Copy(w, res.Body)
+	return err
 }
 
 func (jc *JiraClient) NewIssueFromIface(obj interface{}) (*Issue, error) {
-	issue := new(Issue)
-	key, err := jsonWalker("key", obj)
-	if err != nil {
+	return jc.NewIssueFromIfaceContext(context.Background(), obj)
+}
+
+func (jc *JiraClient) NewIssueFromIfaceContext(ctx context.Context, obj interface{}) (*Issue, error) {
+	var ij issueJSON
+	if err := fromIface(obj, &ij); err != nil {
 		return nil, err
 	}
-	issuetype, err := jsonWalker("fields/issuetype/name", obj)
-	if err != nil {
-		return nil, err
+	return jc.newIssue(ctx, &ij)
+}
+
+//Builds an issue from its payload alone, without its subtasks nor its worklogs.
+func issueFromJSON(ij *issueJSON) (*Issue, error) {
+	if ij.Key == "" {
+		return nil, newIssueError("Bad Issue")
 	}
-	summary, err := jsonWalker("fields/summary", obj)
-	if err != nil {
-		return nil, err
+	f := &ij.Fields
+	issue := &Issue{
+		Key:         ij.Key,
+		Type:        f.IssueType.Name,
+		Summary:     f.Summary,
+		Parent:      f.Parent.Key,
+		Description: f.Description,
+		Status:      f.Status.Name,
+		Assignee:    f.Assignee.Name,
+		Updated:     f.Updated,
+		Points:      f.customField("customfield_10003"),
+		Files:       filesFromJSON(f.Attachment),
+		Comments:    commentsFromJSON(f.Comment.Comments),
+		Links:       linksFromJSON(f.IssueLinks),
+
+		Names:          ij.Names,
+		RenderedFields: ij.RenderedFields,
+		Transitions:    transitionsFromJSON(ij.Transitions),
+		Changelog:      changelogFromJSON(ij.Changelog.Histories),
+	}
+	if f.IssueType.Subtask || issue.Type == "Sub-task" {
+		issue.OriginalEstimate = f.TimeOriginalEstimate
+		issue.RemainingEstimate = f.TimeEstimate
+		issue.TimeSpent = f.TimeSpent
+	} else {
+		issue.OriginalEstimate = f.AggregateTimeOriginalEstimate
+		issue.RemainingEstimate = f.AggregateTimeEstimate
+		issue.TimeSpent = f.AggregateTimeSpent
 	}
+	return issue, nil
+}
 
-	//Is optional
-	parentJS, _ := jsonWalker("fields/parent/key", obj)
-	var parent string
-	parent, _ = parentJS.(string)
+//Builds an issue from its payload, fetching its subtasks with the IncludeSubtasks option
+//and the worklogs Jira didn't embed.
+func (jc *JiraClient) newIssue(ctx context.Context, ij *issueJSON) (*Issue, error) {
+	issue, err := issueFromJSON(ij)
 	if err != nil {
-		parent = ""
-	}
-
-	//Following three things are optional
-	descriptionjs, _ := jsonWalker("fields/description", obj)
-	statusjs, _ := jsonWalker("fields/status/name", obj)
-	assigneejs, _ := jsonWalker("fields/assignee/name", obj)
-
-	ok, ok2, ok3 := true, true, true
-	issue.Key, ok = key.(string)
-	issue.Parent = parent
-	issue.Summary, ok2 = summary.(string)
-	issue.Type, ok3 = issuetype.(string)
-	issue.Description, _ = descriptionjs.(string)
-	issue.Status, _ = statusjs.(string)
-	issue.Assignee, _ = assigneejs.(string)
-	issue.Files = getFileListFromIface(obj)
-	issue.Points, _ = grabCustomField("customfield_10003", obj)
-	if !(ok && ok2 && ok3) {
-		return nil, newIssueError("Bad Issue")
+		return nil, err
 	}
-	if issue.Type != "Sub-task" {
-		OriginalEstimateJs, err := jsonWalker("fields/aggregatetimeoriginalestimate", obj)
-		if err != nil {
-			return nil, err
-		}
-		TimeSpentJs, err := jsonWalker("fields/aggregatetimespent", obj)
-		if err != nil {
-			return nil, err
-		}
-
-		RemainingEstimateJs, err := jsonWalker("fields/aggregatetimeestimate", obj)
-		if err != nil {
-			return nil, err
-		}
-
-		issue.OriginalEstimate, _ = OriginalEstimateJs.(float64)
-		issue.TimeSpent, _ = TimeSpentJs.(float64)
-		issue.RemainingEstimate, _ = RemainingEstimateJs.(float64)
-		if jc.options.IncludeSubtasks {
-			subtasksJS, err := jsonWalker("fields/subtasks", obj)
-			st := []*Issue{}
-			if subtasks, ok := subtasksJS.([]interface{}); ok && err == nil {
-				for _, subtask := range subtasks {
-					k, _ := jsonWalker("key", subtask)
-					i, _ := jc.GetIssue(k.(string))
-					st = append(st, i)
-				}
-				issue.SubTasks = st
-
+	f := &ij.Fields
+	if jc.options.IncludeSubtasks && !f.IssueType.Subtask && issue.Type != "Sub-task" {
+		st := []*Issue{}
+		for _, subtask := range f.Subtasks {
+			i, err := jc.GetIssueContext(ctx, subtask.Key)
+			if err != nil {
+				return nil, err
 			}
+			st = append(st, i)
 		}
-	} else {
-		OriginalEstimateJs, err := jsonWalker("fields/timeoriginalestimate", obj)
-		if err != nil {
-			return nil, err
-		}
-		RemainingEstimateJs, err := jsonWalker("fields/timeremainingestimate", obj)
-		if err != nil {
-			return nil, err
-		}
-		TimeSpentJs, err := jsonWalker("fields/timespent", obj)
+		issue.SubTasks = st
+	}
+	worklogs := f.Worklog.Worklogs
+	if f.Worklog.Total > len(worklogs) {
+		//Jira only embeds the first worklogs, fetch the complete list
+		all, err := jc.getWorklogs(ctx, issue.Key, time.Time{})
 		if err != nil {
 			return nil, err
 		}
-
-		issue.OriginalEstimate, _ = OriginalEstimateJs.(float64)
-		issue.RemainingEstimate, _ = RemainingEstimateJs.(float64)
-		issue.TimeSpent, _ = TimeSpentJs.(float64)
+		worklogs = all
 	}
-	issue.TimeLog = TimeLogForIssue(issue, obj)
-	comms, err := jsonWalker("fields/comment/comments", obj)
-	if err == nil {
-		issue.Comments = commentsFromIFace(comms)
-		if options.Verbose {
-			fmt.Println(issue.Comments)
-		}
-	} else {
-		if options.Verbose {
-			fmt.Println(err)
-
-		}
-		issue.Comments = CommentList{}
+	timelog, err := timeLogFromJSON(issue, worklogs, jc.location())
+	if err != nil {
 		return nil, err
 	}
-
+	issue.TimeLog = timelog
 	return issue, nil
 }
 
-func grabCustomField(fieldname string, obj interface{}) (string, error) {
-	ifields, err := jsonWalker("fields", obj)
-	if err != nil {
-		return "", err
+func commentsFromJSON(comments []commentJSON) CommentList {
+	result := CommentList{}
+	for _, cm := range comments {
+		result = append(result, commentFromJSON(&cm))
 	}
-	fields := ifields.(map[string]interface{})
-	for k, v := range fields {
-		if k == fieldname {
-			return fmt.Sprintf("%v", v), nil
-		}
+	return result
+}
+
+func commentFromJSON(cm *commentJSON) *Comment {
+	return &Comment{
+		Id:               cm.Id,
+		Body:             cm.Body,
+		AuthorName:       cm.Author.DisplayName,
+		AuthorAccountId:  cm.Author.AccountId,
+		AuthorEmail:      cm.Author.EmailAddress,
+		UpdateAuthorName: cm.UpdateAuthor.DisplayName,
+		Created:          cm.Created.Time,
+		Updated:          cm.Updated.Time,
+		Visibility:       cm.Visibility,
 	}
-	return "", errors.New("Field not found")
 }
 
-func commentsFromIFace(obj interface{}) CommentList {
-	result := CommentList{}
-	if comments, ok := obj.([]interface{}); ok {
-		for _, cmj := range comments {
-			if cm, ok := cmj.(map[string]interface{}); ok {
-				if id, ok2 := cm["id"].(string); ok2 {
-					if body, ok3 := cm["body"].(string); ok3 {
-						if author, ok := cm["author"].(map[string]interface{})["displayName"].(string); ok {
-							result = append(result, &Comment{Id: id, Body: body, AuthorName: author})
-						}
-					}
-
-				}
-			}
-		}
+func transitionsFromJSON(transitions []transitionJSON) []*Transition {
+	result := make([]*Transition, 0, len(transitions))
+	for _, tx := range transitions {
+		result = append(result, &Transition{Id: tx.Id, Name: tx.Name, ToStatus: tx.To.Name})
 	}
 	return result
 }
 
-func getFileListFromIface(obj interface{}) IssueFileList {
-	rez := make(IssueFileList, 0)
-	attachmentsjs, err := jsonWalker("fields/attachment", obj)
-	if err != nil {
-		return rez
+func changelogFromJSON(histories []historyJSON) []*ChangeHistory {
+	result := make([]*ChangeHistory, 0, len(histories))
+	for _, h := range histories {
+		ch := &ChangeHistory{Id: h.Id, Author: h.Author.DisplayName, Created: h.Created.Time}
+		for _, item := range h.Items {
+			ch.Items = append(ch.Items, ChangeItem(item))
+		}
+		result = append(result, ch)
 	}
-	attachments, ok := attachmentsjs.([]interface{})
-	if !ok {
-		return rez
+	return result
+}
+
+func fileFromJSON(att *attachmentJSON) *IssueFile {
+	return &IssueFile{
+		Id:       att.Id,
+		Filename: att.Filename,
+		Size:     att.Size,
+		MimeType: att.MimeType,
+		Author:   att.Author.Name,
+		Created:  att.Created.Time,
+		Content:  att.Content,
+		Self:     att.Self,
 	}
+}
 
-	for _, v := range attachments {
-		filename, err := jsonWalker("filename", v)
-		file, err := jsonWalker("content", v)
-		self_js, err := jsonWalker("self", v)
-		if err != nil {
-			continue
-		}
-		filenamestr, ok := filename.(string)
-		filestring, ok2 := file.(string)
-		self, ok3 := self_js.(string)
-		if ok && ok2 && ok3 {
-			rez = append(rez, &IssueFile{name: filenamestr, url: filestring, self: self})
-		}
+func filesFromJSON(attachments []attachmentJSON) IssueFileList {
+	rez := make(IssueFileList, 0)
+	for _, att := range attachments {
+		rez = append(rez, fileFromJSON(&att))
 	}
 	return rez
 }
 
 func (jc *JiraClient) GetIssue(issueKey string) (*Issue, error) {
+	return jc.GetIssueContext(context.Background(), issueKey)
+}
 
-	resp, err := jc.Get(fmt.Sprintf("https://%s/rest/api/2/issue/%s", jc.Server, issueKey))
-	if err != nil {
-		panic(err)
+func (jc *JiraClient) GetIssueContext(ctx context.Context, issueKey string) (*Issue, error) {
+	return jc.GetIssueWithOptionsContext(ctx, issueKey, nil)
+}
+
+//Limits what GetIssueWithOptions fetches
+type GetIssueOptions struct {
+	Fields []string //Fields to fetch, all of them when empty
+	Expand []string //Extra information to fetch: changelog, renderedFields, names, transitions...
+}
+
+func (gio *GetIssueOptions) params() url.Values {
+	params := url.Values{}
+	if gio == nil {
+		return params
 	}
-	obj, err := JsonToInterface(resp.Body)
-	iss, err := jc.NewIssueFromIface(obj)
-	if err != nil {
+	if len(gio.Fields) > 0 {
+		params.Set("fields", strings.Join(gio.Fields, ","))
+	}
+	if len(gio.Expand) > 0 {
+		params.Set("expand", strings.Join(gio.Expand, ","))
+	}
+	return params
+}
+
+func (jc *JiraClient) GetIssueWithOptions(issueKey string, opts *GetIssueOptions) (*Issue, error) {
+	return jc.GetIssueWithOptionsContext(context.Background(), issueKey, opts)
+}
+
+func (jc *JiraClient) GetIssueWithOptionsContext(ctx context.Context, issueKey string, opts *GetIssueOptions) (*Issue, error) {
+	u := jc.apiUrl("issue/%s", issueKey)
+	if params := opts.params(); len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	var ij issueJSON
+	if err := jc.getJSON(ctx, u, &ij); err != nil {
 		return nil, err
 	}
-	return iss, nil
+	return jc.newIssue(ctx, &ij)
 }
 
 func tagsFromStringSlice(tags []string) []interface{} {
@@ -526,28 +526,35 @@ func tagsFromStringSlice(tags []string) []interface{} {
 }
 
 func (jc *JiraClient) AddTags(issuekey string, tags []string) error {
+	return jc.AddTagsContext(context.Background(), issuekey, tags)
+}
+
+func (jc *JiraClient) AddTagsContext(ctx context.Context, issuekey string, tags []string) error {
 	postjs := map[string]interface{}{"labels": tagsFromStringSlice(tags)}
 
-	return jc.UpdateIssue(issuekey, postjs)
+	return jc.UpdateIssueContext(ctx, issuekey, postjs)
 
 }
 
 func (jc *JiraClient) UpdateIssue(issuekey string, postjs map[string]interface{}) error {
+	return jc.UpdateIssueContext(context.Background(), issuekey, postjs)
+}
+
+func (jc *JiraClient) UpdateIssueContext(ctx context.Context, issuekey string, postjs map[string]interface{}) error {
 	postdata, err := json.Marshal(map[string]interface{}{"update": postjs})
 
 	if err != nil {
 		return err
 	}
-	resp, err := jc.Put(fmt.Sprintf("https://%s/rest/api/latest/issue/%s", jc.Server, issuekey), "application/json", bytes.NewBuffer(postdata))
+	resp, err := jc.PutContext(ctx, jc.serverUrl("rest/api/latest/issue/%s", issuekey), "application/json", bytes.NewBuffer(postdata))
 
 	if err != nil {
 		return err
 	}
-	if resp.StatusCode != 204 {
-		log.Println(resp.StatusCode)
-		return &JiraClientError{"Bad request"}
+	if err = decodeResponse(resp, nil); err != nil {
+		return err
 	}
-	log.Println(fmt.Sprintf("Issue %s updated!", issuekey))
+	jc.log().Infof("Issue %s updated!", issuekey)
 	return nil
 }
 
@@ -556,40 +563,56 @@ func (jc *JiraClient) Client() *http.Client {
 }
 
 func (jc *JiraClient) Get(url string) (*http.Response, error) {
-	req, err := jc.newRequest("GET", url, "", nil)
+	return jc.GetContext(context.Background(), url)
+}
+
+func (jc *JiraClient) GetContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := jc.newRequest(ctx, "GET", url, "", nil)
 	if err != nil {
 		return nil, err
 	}
-	return jc.client.Do(req)
+	return jc.do(req)
 }
 
 func (jc *JiraClient) Post(url, mimetype string, rdr io.Reader) (*http.Response, error) {
-	req, err := jc.newRequest("POST", url, mimetype, rdr)
-	req.Header.Add("X-Atlassian-Token", "nocheck")
+	return jc.PostContext(context.Background(), url, mimetype, rdr)
+}
+
+func (jc *JiraClient) PostContext(ctx context.Context, url, mimetype string, rdr io.Reader) (*http.Response, error) {
+	req, err := jc.newRequest(ctx, "POST", url, mimetype, rdr)
 	if err != nil {
 		return nil, err
 	}
-	return jc.client.Do(req)
+	req.Header.Add("X-Atlassian-Token", "nocheck")
+	return jc.do(req)
 }
 
 func (jc *JiraClient) Put(url, mimetype string, rdr io.Reader) (*http.Response, error) {
-	req, err := jc.newRequest("PUT", url, mimetype, rdr)
+	return jc.PutContext(context.Background(), url, mimetype, rdr)
+}
+
+func (jc *JiraClient) PutContext(ctx context.Context, url, mimetype string, rdr io.Reader) (*http.Response, error) {
+	req, err := jc.newRequest(ctx, "PUT", url, mimetype, rdr)
 	if err != nil {
 		return nil, err
 	}
-	return jc.client.Do(req)
+	return jc.do(req)
 }
 
 func (jc *JiraClient) Delete(url, mimetype string, rdr io.Reader) (*http.Response, error) {
-	req, err := jc.newRequest("DELETE", url, mimetype, nil)
+	return jc.DeleteContext(context.Background(), url, mimetype, rdr)
+}
+
+func (jc *JiraClient) DeleteContext(ctx context.Context, url, mimetype string, rdr io.Reader) (*http.Response, error) {
+	req, err := jc.newRequest(ctx, "DELETE", url, mimetype, nil)
 	if err != nil {
 		return nil, err
 	}
-	return jc.client.Do(req)
+	return jc.do(req)
 }
 
-func (jc *JiraClient) newRequest(verb, url, mimetype string, rdr io.Reader) (*http.Request, error) {
-	req, err := http.NewRequest(verb, url, rdr)
+func (jc *JiraClient) newRequest(ctx context.Context, verb, url, mimetype string, rdr io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, verb, url, rdr)
 	if err != nil {
 		return nil, err
 	}
@@ -604,159 +627,126 @@ func (jc *JiraClient) newRequest(verb, url, mimetype string, rdr io.Reader) (*ht
 	return req, nil
 }
 
-type JiraClientError struct {
-	msg string
-}
-
-func (jce *JiraClientError) Error() string {
-	return jce.msg
-}
-
-//Helper function to read a json input and unmarshal it to an interface{} object
-func JsonToInterface(reader io.Reader) (interface{}, error) {
-	rdr := bufio.NewReader(reader)
-	js := make([]string, 0)
-	for {
-		s, err := rdr.ReadString('\n')
-		js = append(js, s)
-		if err != nil {
-			break
+//Sends the request, retrying transient failures according to the client's RetryPolicy.
+func (jc *JiraClient) do(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := jc.send(req)
+		if !isTransient(resp, err) || req.Context().Err() != nil || !jc.Retry.canRetry(req, attempt) {
+			return resp, err
 		}
-
-	}
-	njs := strings.Join(js, "")
-	var obj interface{}
-	err := json.Unmarshal([]byte(njs), &obj)
-	if err != nil {
-		return nil, err
-	}
-	return obj, nil
-}
-
-//Helper function to navigate an unmarshalled json interface{} object.
-//Takes in a path in the form of "path/to/field".
-//Doesn't deal with arrays.
-func jsonWalker(path string, json interface{}) (interface{}, error) {
-	p := strings.Split(path, "/")
-	tmpval := json
-	for i, subpath := range p {
-		submap, ok := tmpval.(map[string]interface{})
+		wait, ok := jc.Retry.delay(resp, attempt)
 		if !ok {
-			return nil, errors.New(fmt.Sprintf("Bad path, %s is not a map[string]interface{}", p[i-1]))
+			jc.log().Debugf("%s %s: not retrying, server asked to wait %s", req.Method, req.URL, wait)
+			return resp, err
 		}
-		if i < (len(p) - 1) {
-			tmpval = submap[subpath]
-		} else {
-			return submap[subpath], nil
+		if resp != nil {
+			drain(resp)
+		}
+		jc.log().Debugf("%s %s: retrying in %s", req.Method, req.URL, wait)
+		if err = sleepContext(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		if req, err = jc.rewind(req); err != nil {
+			return nil, err
 		}
 	}
-	return nil, errors.New("Woooops")
 }
 
-func (jc *JiraClient) GetTaskTypes() (map[string]map[string]string, error) {
-	resp, err := jc.Get(fmt.Sprintf("https://%s/rest/api/2/issue/createmeta", jc.Server))
+//Sends a single attempt of the request when the client's RateLimiter allows it, tracing it to the debug log.
+func (jc *JiraClient) send(req *http.Request) (*http.Response, error) {
+	release, err := jc.Limiter.acquire(req.Context())
 	if err != nil {
 		return nil, err
 	}
-	obj, err := JsonToInterface(resp.Body)
+	jc.log().Debugf("%s %s", req.Method, req.URL)
+	resp, err := jc.client.Do(req)
+	release()
 	if err != nil {
+		jc.log().Debugf("%s %s: %s", req.Method, req.URL, err)
 		return nil, err
 	}
-	projs, err := jsonWalker("projects", obj)
-	if err != nil {
+	jc.log().Debugf("%s %s: %s", req.Method, req.URL, resp.Status)
+	return resp, nil
+}
+
+type JiraClientError struct {
+	msg string
+}
+
+func (jce *JiraClientError) Error() string {
+	return jce.msg
+}
+
+func (jc *JiraClient) GetTaskTypes() (map[string]map[string]string, error) {
+	return jc.GetTaskTypesContext(context.Background())
+}
+
+func (jc *JiraClient) GetTaskTypesContext(ctx context.Context) (map[string]map[string]string, error) {
+	var meta createMetaJSON
+	if err := jc.getJSON(ctx, jc.apiUrl("issue/createmeta"), &meta); err != nil {
 		return nil, err
 	}
-	if probjs, ok := projs.([]interface{}); ok {
-		projmap := map[string]map[string]string{}
-		for _, v := range probjs {
-			projnamejs, _ := jsonWalker("name", v)
-			projkeyjs, _ := jsonWalker("key", v)
-			projkey, _ := projkeyjs.(string)
-			if projname, ok := projnamejs.(string); ok {
-				projmap[projname] = map[string]string{}
-				if projkey != "" {
-					projmap[projkey] = projmap[projname]
-				}
-				issuesjs, _ := jsonWalker("issuetypes", v)
-				if issues, ok := issuesjs.([]interface{}); ok {
-					for _, issuetype := range issues {
-						typenamejs, err := jsonWalker("name", issuetype)
-						if err != nil {
-							continue
-						}
-						if typename, ok := typenamejs.(string); ok {
-							projmap[projname][strings.Replace(strings.ToLower(typename), " ", "-", -1)] = typename
-							//							if projkey != "" {
-							//								projmap[projkey][strings.Replace(strings.ToLower(typename), " ", "-", -1)] = typename
-							//							}
-						}
-					}
-				}
-			}
+	projmap := map[string]map[string]string{}
+	for _, proj := range meta.Projects {
+		if proj.Name == "" {
+			continue
 		}
-		if jc.options.Verbose {
-			fmt.Println(projmap)
+		projmap[proj.Name] = map[string]string{}
+		if proj.Key != "" {
+			projmap[proj.Key] = projmap[proj.Name]
+		}
+		for _, issuetype := range proj.IssueTypes {
+			if issuetype.Name != "" {
+				projmap[proj.Name][strings.Replace(strings.ToLower(issuetype.Name), " ", "-", -1)] = issuetype.Name
+			}
 		}
-		return projmap, nil
 	}
-
-	return map[string]map[string]string{}, nil
+	jc.log().Debugf("%v", projmap)
+	return projmap, nil
 }
 
 func (jc *JiraClient) GetProjList() ([]string, error) {
-	resp, err := jc.Get(fmt.Sprintf("https://%s/rest/api/2/project", jc.Server))
-	if err != nil {
-		return nil, err
+	return jc.GetProjListContext(context.Background())
+}
+
+func (jc *JiraClient) GetProjListContext(ctx context.Context) ([]string, error) {
+	var projs []struct {
+		Key string `json:"key"`
 	}
-	obj, err := JsonToInterface(resp.Body)
-	if err != nil {
+	if err := jc.getJSON(ctx, jc.apiUrl("project"), &projs); err != nil {
 		return nil, err
 	}
 	result := []string{}
-	if projs, ok := obj.([]interface{}); ok {
-		for _, p := range projs {
-			result = append(result, p.(map[string]interface{})["key"].(string))
-		}
-	}
-	if jc.options.Verbose {
-		log.Println(result)
+	for _, p := range projs {
+		result = append(result, p.Key)
 	}
+	jc.log().Debugf("%v", result)
 	return result, nil
 }
 
 func (jc *JiraClient) GetProjects() (map[string]JiraProject, error) {
-	projmap := map[string]JiraProject{}
-	resp, err := jc.Get(fmt.Sprintf("https://%s/rest/api/2/issue/createmeta", jc.Server))
-	if err != nil {
-		return nil, err
-	}
-	obj, err := JsonToInterface(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	projs, err := jsonWalker("projects", obj)
-	if err != nil {
+	return jc.GetProjectsContext(context.Background())
+}
+
+func (jc *JiraClient) GetProjectsContext(ctx context.Context) (map[string]JiraProject, error) {
+	var meta createMetaJSON
+	if err := jc.getJSON(ctx, jc.apiUrl("issue/createmeta"), &meta); err != nil {
 		return nil, err
 	}
-	if probjs, ok := projs.([]interface{}); ok {
-		for _, v := range probjs {
-			projnamejs, _ := jsonWalker("name", v)
-			projkeyjs, _ := jsonWalker("key", v)
-			projidjs, _ := jsonWalker("id", v)
-			projname, _ := projnamejs.(string)
-			projkey, _ := projkeyjs.(string)
-			projid, _ := projidjs.(string)
-			projmap[projname] = JiraProject{Id: projid, Name: projname, Key: projkey}
-			projmap[projkey] = projmap[projname]
-		}
+	projmap := map[string]JiraProject{}
+	for _, proj := range meta.Projects {
+		projmap[proj.Name] = JiraProject{Id: proj.Id, Name: proj.Name, Key: proj.Key}
+		projmap[proj.Key] = projmap[proj.Name]
 	}
-
 	return projmap, nil
-
 }
 
 func (jc *JiraClient) GetTaskType(friendlyname string) (string, error) {
-	projmap, err := jc.GetTaskTypes()
+	return jc.GetTaskTypeContext(context.Background(), friendlyname)
+}
+
+func (jc *JiraClient) GetTaskTypeContext(ctx context.Context, friendlyname string) (string, error) {
+	projmap, err := jc.GetTaskTypesContext(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -764,21 +754,22 @@ func (jc *JiraClient) GetTaskType(friendlyname string) (string, error) {
 	if taskname, ok := projmap[jc.options.Projects[0]][friendlyname]; ok {
 		return taskname, nil
 	} else {
-		if jc.options.Verbose {
-			fmt.Println(projmap[jc.options.Projects[0]])
-		}
-
+		jc.log().Debugf("%v", projmap[jc.options.Projects[0]])
 	}
 
 	return "", &JiraClientError{fmt.Sprintf("Task name not found for friendly name %s.", friendlyname)}
 }
 
 func (jc *JiraClient) CreateTask(project string, nto *NewTaskOptions) error {
-	tt, err := jc.GetTaskType(nto.TaskType)
+	return jc.CreateTaskContext(context.Background(), project, nto)
+}
+
+func (jc *JiraClient) CreateTaskContext(ctx context.Context, project string, nto *NewTaskOptions) error {
+	tt, err := jc.GetTaskTypeContext(ctx, nto.TaskType)
 	if err != nil {
 		return err
 	}
-	projmap, err := jc.GetProjects()
+	projmap, err := jc.GetProjectsContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -819,36 +810,42 @@ func (jc *JiraClient) CreateTask(project string, nto *NewTaskOptions) error {
 		fields[fname] = map[string]interface{}{"value": fval}
 	}
 
-	iss, err := json.Marshal(map[string]interface{}{
-		"fields": fields})
-	if err != nil {
+	jc.log().Debugf("%v", fields)
+	var created issueRefJSON
+	if err := jc.sendJSON(ctx, "POST", jc.apiUrl("issue"), map[string]interface{}{"fields": fields}, &created); err != nil {
 		return err
 	}
-	if jc.options.Verbose {
-		fmt.Println(string(iss))
-	}
-	resp, err := jc.Post(fmt.Sprintf("https://%s/rest/api/2/issue", jc.Server), "application/json", bytes.NewBuffer(iss))
-	if err != nil {
-		return err
-	}
-	s, _ := ioutil.ReadAll(resp.Body)
-	if resp.StatusCode != 201 {
-
-		return &IssueError{fmt.Sprintf("%d: %s", resp.StatusCode, string(s))}
-	}
-	var js interface{}
-	err = json.Unmarshal(s, &js)
-	if err != nil {
-		return err
-	}
-	keyjs, _ := jsonWalker("key", js)
-	key, _ := keyjs.(string)
-	log.Println(fmt.Sprintf("%s successfully created!", key))
+	jc.log().Infof("%s successfully created!", created.Key)
 	return nil
 }
 
 func (jc *JiraClient) issueUrl() string {
-	return fmt.Sprintf("https://%s/rest/api/2/issue", jc.Server)
+	return jc.apiUrl("issue")
+}
+
+//Returns the base url of the Jira server, without a trailing slash.
+func (jc *JiraClient) BaseUrl() string {
+	return baseUrl(jc.Server)
+}
+
+//Builds an absolute url from a path relative to the server's base url, e.g. "rest/api/2/search".
+func (jc *JiraClient) serverUrl(format string, a ...interface{}) string {
+	return jc.BaseUrl() + "/" + strings.TrimLeft(fmt.Sprintf(format, a...), "/")
+}
+
+//Builds an absolute url to the REST API from a path relative to "rest/api/2/".
+func (jc *JiraClient) apiUrl(format string, a ...interface{}) string {
+	return jc.serverUrl("rest/api/2/"+format, a...)
+}
+
+//Normalizes the server option into a base url.
+//A bare domain name ("jira.example.com") is assumed to be served over https at the root,
+//while a full url may specify its own scheme, port and context path ("http://localhost:8080/jira/").
+func baseUrl(server string) string {
+	if !strings.Contains(server, "://") {
+		server = "https://" + server
+	}
+	return strings.TrimRight(server, "/")
 }
 
 func PrintHtml(issues []*Issue) ([]byte, error) {
@@ -883,6 +880,10 @@ type NewTaskOptions struct {
 }
 
 func (jc *JiraClient) ChangeRank(rankthese []string, before_or_after string, target string) error {
+	return jc.ChangeRankContext(context.Background(), rankthese, before_or_after, target)
+}
+
+func (jc *JiraClient) ChangeRankContext(ctx context.Context, rankthese []string, before_or_after string, target string) error {
 	b := bytes.NewBuffer([]byte{})
 	enc := json.NewEncoder(b)
 	//	keys := make([]string, 0, len(rankthese))
@@ -899,20 +900,13 @@ func (jc *JiraClient) ChangeRank(rankthese []string, before_or_after string, tar
 		return fmt.Errorf("before_or_after needs to be set to either 'before' or 'after'.")
 	}
 	err := enc.Encode(map[string]interface{}{"issueKeys": rankthese, "customFieldId": 10560, b_o_f: target})
-	fmt.Println(b.String())
+	jc.log().Debugf("%s", b)
 	if err != nil {
 		return err
 	}
-	res, err := jc.Put(fmt.Sprintf("https://%s/rest/greenhopper/1.0/api/rank/%s/", jc.Server, before_or_after), "application/json", b)
+	res, err := jc.PutContext(ctx, jc.serverUrl("rest/greenhopper/1.0/api/rank/%s/", before_or_after), "application/json", b)
 	if err != nil {
 		return err
 	}
-	if res.StatusCode >= 400 {
-		msg, err := ioutil.ReadAll(res.Body)
-		if err != nil {
-			return nil
-		}
-		return fmt.Errorf("%s", msg)
-	}
-	return nil
+	return decodeResponse(res, nil)
 }
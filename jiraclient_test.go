@@ -0,0 +1,98 @@
+package libgojira
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+//Returns a client talking to a fake Jira served under the /jira context path.
+func newTestClient(t *testing.T, h http.HandlerFunc) *JiraClient {
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return NewJiraClient(Options{Server: srv.URL + "/jira/"})
+}
+
+const testIssue = `{"id":"10001","key":"ABC-1","fields":{"summary":"Fix it","issuetype":{"name":"Bug"},"status":{"name":"Open"}}}`
+
+func TestBaseUrl(t *testing.T) {
+	for server, want := range map[string]string{
+		"jira.example.com":                "https://jira.example.com",
+		"https://jira.example.com/":       "https://jira.example.com",
+		"http://localhost:8080/jira":      "http://localhost:8080/jira",
+		"http://localhost:8080/jira///":   "http://localhost:8080/jira",
+		"https://example.com/tools/jira/": "https://example.com/tools/jira",
+	} {
+		if got := baseUrl(server); got != want {
+			t.Errorf("baseUrl(%q) = %q, want %q", server, got, want)
+		}
+	}
+}
+
+func TestGetIssueContextPath(t *testing.T) {
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jira/rest/api/2/issue/ABC-1" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(testIssue))
+	})
+	issue, err := jc.GetIssue("ABC-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.Key != "ABC-1" || issue.Summary != "Fix it" || issue.Type != "Bug" {
+		t.Errorf("unexpected issue %+v", issue)
+	}
+}
+
+func TestSearchContextPath(t *testing.T) {
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jira/rest/api/2/search" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if jql := r.URL.Query().Get("jql"); jql != `project = "ABC"` {
+			t.Errorf("unexpected jql %q", jql)
+		}
+		w.Write([]byte(`{"startAt":0,"maxResults":50,"total":1,"issues":[` + testIssue + `]}`))
+	})
+	issues, err := jc.Search(&SearchOptions{JQL: `project = "ABC"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Key != "ABC-1" {
+		t.Errorf("unexpected issues %v", issues)
+	}
+}
+
+func TestResponseBodiesReleased(t *testing.T) {
+	var conns int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//Jira answers some updates with a body the client has no use for
+		w.Write([]byte(`{"id":"10001","key":"ABC-1"}`))
+	}))
+	srv.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+	jc := NewJiraClient(Options{Server: srv.URL})
+	for i := 0; i < 5; i++ {
+		if err := jc.AddTags("ABC-1", []string{"tag"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := jc.DeleteLink("10000"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&conns); n != 1 {
+		t.Errorf("opened %d connections, want 1", n)
+	}
+}
@@ -0,0 +1,316 @@
+package libgojira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+//Payloads returned by the Jira REST API, decoded with encoding/json.
+//Fields missing from a payload are left to their zero value.
+
+//Timestamp in one of the formats used by Jira, JIRA_TIME_FORMAT being the most common.
+type jiraTime struct {
+	time.Time
+}
+
+var jiraTimeFormats = []string{JIRA_TIME_FORMAT, "2006-01-02T15:04:05.000Z0700", time.RFC3339Nano, "2006-01-02"}
+
+func parseJiraTime(s string) (time.Time, error) {
+	var err error
+	for _, format := range jiraTimeFormats {
+		var t time.Time
+		if t, err = time.Parse(format, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("bad Jira timestamp %q: %w", s, err)
+}
+
+func (jt *jiraTime) UnmarshalJSON(b []byte) error {
+	var s *string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == nil || *s == "" {
+		jt.Time = time.Time{}
+		return nil
+	}
+	t, err := parseJiraTime(*s)
+	if err != nil {
+		return err
+	}
+	jt.Time = t
+	return nil
+}
+
+type userJSON struct {
+	Name         string `json:"name"`
+	Key          string `json:"key"`
+	AccountId    string `json:"accountId"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+type namedJSON struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type issueTypeJSON struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Subtask bool   `json:"subtask"`
+}
+
+type issueRefJSON struct {
+	Id  string `json:"id"`
+	Key string `json:"key"`
+}
+
+type attachmentJSON struct {
+	Id       string   `json:"id"`
+	Self     string   `json:"self"`
+	Filename string   `json:"filename"`
+	Author   userJSON `json:"author"`
+	Created  jiraTime `json:"created"`
+	Size     int64    `json:"size"`
+	MimeType string   `json:"mimeType"`
+	Content  string   `json:"content"`
+}
+
+type commentJSON struct {
+	Id           string      `json:"id"`
+	Self         string      `json:"self"`
+	Body         string      `json:"body"`
+	Author       userJSON    `json:"author"`
+	UpdateAuthor userJSON    `json:"updateAuthor"`
+	Created      jiraTime    `json:"created"`
+	Updated      jiraTime    `json:"updated"`
+	Visibility   *Visibility `json:"visibility"`
+}
+
+type commentPageJSON struct {
+	StartAt    int           `json:"startAt"`
+	MaxResults int           `json:"maxResults"`
+	Total      int           `json:"total"`
+	Comments   []commentJSON `json:"comments"`
+}
+
+type worklogJSON struct {
+	Id               string   `json:"id"`
+	IssueId          string   `json:"issueId"`
+	Self             string   `json:"self"`
+	Author           userJSON `json:"author"`
+	Comment          string   `json:"comment"`
+	Started          jiraTime `json:"started"`
+	TimeSpentSeconds int      `json:"timeSpentSeconds"`
+}
+
+type worklogPageJSON struct {
+	StartAt    int           `json:"startAt"`
+	MaxResults int           `json:"maxResults"`
+	Total      int           `json:"total"`
+	Worklogs   []worklogJSON `json:"worklogs"`
+}
+
+type worklogChangesJSON struct {
+	Values []struct {
+		WorklogId   int64 `json:"worklogId"`
+		UpdatedTime int64 `json:"updatedTime"`
+	} `json:"values"`
+	Since    int64  `json:"since"`
+	Until    int64  `json:"until"`
+	LastPage bool   `json:"lastPage"`
+	NextPage string `json:"nextPage"`
+}
+
+type issueFieldsJSON struct {
+	Summary                       string           `json:"summary"`
+	Description                   string           `json:"description"`
+	IssueType                     issueTypeJSON    `json:"issuetype"`
+	Status                        namedJSON        `json:"status"`
+	Assignee                      userJSON         `json:"assignee"`
+	Parent                        issueRefJSON     `json:"parent"`
+	Updated                       string           `json:"updated"`
+	Attachment                    []attachmentJSON `json:"attachment"`
+	Comment                       commentPageJSON  `json:"comment"`
+	Worklog                       worklogPageJSON  `json:"worklog"`
+	Subtasks                      []issueRefJSON   `json:"subtasks"`
+	IssueLinks                    []issueLinkJSON  `json:"issuelinks"`
+	TimeOriginalEstimate          float64          `json:"timeoriginalestimate"`
+	TimeEstimate                  float64          `json:"timeestimate"`
+	TimeSpent                     float64          `json:"timespent"`
+	AggregateTimeOriginalEstimate float64          `json:"aggregatetimeoriginalestimate"`
+	AggregateTimeEstimate         float64          `json:"aggregatetimeestimate"`
+	AggregateTimeSpent            float64          `json:"aggregatetimespent"`
+
+	Raw map[string]json.RawMessage `json:"-"` //Every field as sent, custom fields included
+}
+
+func (f *issueFieldsJSON) UnmarshalJSON(b []byte) error {
+	type plain issueFieldsJSON
+	if err := json.Unmarshal(b, (*plain)(f)); err != nil {
+		return err
+	}
+	return json.Unmarshal(b, &f.Raw)
+}
+
+//Returns a field's value formatted as a string, or "" when it is absent or null.
+func (f *issueFieldsJSON) customField(name string) string {
+	raw, ok := f.Raw[name]
+	if !ok {
+		return ""
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil || v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+type linkTypeJSON struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Inward  string `json:"inward"`
+	Outward string `json:"outward"`
+}
+
+type linkedIssueJSON struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary string    `json:"summary"`
+		Status  namedJSON `json:"status"`
+	} `json:"fields"`
+}
+
+type issueLinkJSON struct {
+	Id           string           `json:"id"`
+	Type         linkTypeJSON     `json:"type"`
+	InwardIssue  *linkedIssueJSON `json:"inwardIssue"`
+	OutwardIssue *linkedIssueJSON `json:"outwardIssue"`
+}
+
+type historyJSON struct {
+	Id      string   `json:"id"`
+	Author  userJSON `json:"author"`
+	Created jiraTime `json:"created"`
+	Items   []struct {
+		Field      string `json:"field"`
+		From       string `json:"from"`
+		FromString string `json:"fromString"`
+		To         string `json:"to"`
+		ToString   string `json:"toString"`
+	} `json:"items"`
+}
+
+type issueJSON struct {
+	Id             string                 `json:"id"`
+	Key            string                 `json:"key"`
+	Self           string                 `json:"self"`
+	Fields         issueFieldsJSON        `json:"fields"`
+	Names          map[string]string      `json:"names"`
+	RenderedFields map[string]interface{} `json:"renderedFields"`
+	Transitions    []transitionJSON       `json:"transitions"`
+	Changelog      struct {
+		Histories []historyJSON `json:"histories"`
+	} `json:"changelog"`
+}
+
+type transitionJSON struct {
+	Id     string    `json:"id"`
+	Name   string    `json:"name"`
+	To     namedJSON `json:"to"`
+	Fields map[string]struct {
+		AllowedValues []namedJSON `json:"allowedValues"`
+	} `json:"fields"`
+}
+
+type transitionsJSON struct {
+	Transitions []transitionJSON `json:"transitions"`
+}
+
+type searchResultJSON struct {
+	StartAt    int               `json:"startAt"`
+	MaxResults int               `json:"maxResults"`
+	Total      int               `json:"total"`
+	Issues     []json.RawMessage `json:"issues"`
+	Names      map[string]string `json:"names"`
+}
+
+type createMetaJSON struct {
+	Projects []struct {
+		Id         string          `json:"id"`
+		Key        string          `json:"key"`
+		Name       string          `json:"name"`
+		IssueTypes []issueTypeJSON `json:"issuetypes"`
+	} `json:"projects"`
+}
+
+//Decodes the body of a successful response into v, or returns an *APIError.
+//The body is always consumed and closed, so the connection can be reused.
+func decodeResponse(resp *http.Response, v interface{}) error {
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+//GETs url and decodes the JSON response into v.
+func (jc *JiraClient) getJSON(ctx context.Context, url string, v interface{}) error {
+	resp, err := jc.GetContext(ctx, url)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(resp, v)
+}
+
+//Sends in as a JSON body with the given verb and decodes the JSON response into out, unless out is nil.
+func (jc *JiraClient) sendJSON(ctx context.Context, verb, url string, in, out interface{}) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	var resp *http.Response
+	switch verb {
+	case "POST":
+		resp, err = jc.PostContext(ctx, url, "application/json", bytes.NewReader(b))
+	case "PUT":
+		resp, err = jc.PutContext(ctx, url, "application/json", bytes.NewReader(b))
+	default:
+		return fmt.Errorf("unsupported verb %s", verb)
+	}
+	if err != nil {
+		return err
+	}
+	return decodeResponse(resp, out)
+}
+
+//Decodes a generic JSON value, such as one returned by JsonToInterface, into v.
+func fromIface(obj interface{}, v interface{}) error {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(bytes.NewReader(b)).Decode(v)
+}
+
+//Helper function to read a json input and unmarshal it to an interface{} object
+func JsonToInterface(reader io.Reader) (interface{}, error) {
+	var obj interface{}
+	if err := json.NewDecoder(reader).Decode(&obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
@@ -0,0 +1,246 @@
+package libgojira
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//A JQL condition. Clauses rendering to an empty string are ignored by And and Or.
+type JQLClause interface {
+	String() string
+}
+
+//JQL function call used as a value, rendered without quotes, e.g. JQLFunc("openSprints()").
+type JQLFunc string
+
+//Format used to render time.Time values.
+const JQL_TIME_FORMAT = "2006/01/02 15:04"
+
+var jqlIdentifier = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.]*|cf\[[0-9]+\])$`)
+
+//Quotes a string for use in JQL, escaping backslashes and double quotes.
+func jqlQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	return `"` + s + `"`
+}
+
+func jqlField(field string) string {
+	if jqlIdentifier.MatchString(field) {
+		return field
+	}
+	return jqlQuote(field)
+}
+
+func jqlValue(v interface{}) string {
+	switch val := v.(type) {
+	case JQLFunc:
+		return string(val)
+	case string:
+		return jqlQuote(val)
+	case time.Time:
+		return jqlQuote(val.Format(JQL_TIME_FORMAT))
+	case int, int32, int64, uint, uint32, uint64, float32, float64:
+		return fmt.Sprintf("%v", val)
+	}
+	return jqlQuote(fmt.Sprintf("%v", v))
+}
+
+type jqlCondition struct {
+	field  string
+	op     string
+	values []interface{}
+	list   bool
+}
+
+func (c *jqlCondition) String() string {
+	if c.list {
+		if len(c.values) == 0 {
+			return ""
+		}
+		vals := make([]string, 0, len(c.values))
+		for _, v := range c.values {
+			vals = append(vals, jqlValue(v))
+		}
+		return fmt.Sprintf("%s %s (%s)", jqlField(c.field), c.op, strings.Join(vals, ", "))
+	}
+	if len(c.values) == 0 {
+		return fmt.Sprintf("%s %s", jqlField(c.field), c.op)
+	}
+	return fmt.Sprintf("%s %s %s", jqlField(c.field), c.op, jqlValue(c.values[0]))
+}
+
+//field = value
+func Eq(field string, value interface{}) JQLClause {
+	return &jqlCondition{field: field, op: "=", values: []interface{}{value}}
+}
+
+//field != value
+func NotEq(field string, value interface{}) JQLClause {
+	return &jqlCondition{field: field, op: "!=", values: []interface{}{value}}
+}
+
+//field in (values...). Renders to nothing when values is empty.
+func In(field string, values ...interface{}) JQLClause {
+	return &jqlCondition{field: field, op: "in", values: values, list: true}
+}
+
+//field not in (values...). Renders to nothing when values is empty.
+func NotIn(field string, values ...interface{}) JQLClause {
+	return &jqlCondition{field: field, op: "not in", values: values, list: true}
+}
+
+//field ~ text
+func Contains(field, text string) JQLClause {
+	return &jqlCondition{field: field, op: "~", values: []interface{}{text}}
+}
+
+//field !~ text
+func NotContains(field, text string) JQLClause {
+	return &jqlCondition{field: field, op: "!~", values: []interface{}{text}}
+}
+
+//field < value. Dates may be given as time.Time or relative strings such as "-7d".
+func Lt(field string, value interface{}) JQLClause {
+	return &jqlCondition{field: field, op: "<", values: []interface{}{value}}
+}
+
+//field <= value
+func Lte(field string, value interface{}) JQLClause {
+	return &jqlCondition{field: field, op: "<=", values: []interface{}{value}}
+}
+
+//field > value
+func Gt(field string, value interface{}) JQLClause {
+	return &jqlCondition{field: field, op: ">", values: []interface{}{value}}
+}
+
+//field >= value
+func Gte(field string, value interface{}) JQLClause {
+	return &jqlCondition{field: field, op: ">=", values: []interface{}{value}}
+}
+
+//field is EMPTY
+func IsEmpty(field string) JQLClause {
+	return &jqlCondition{field: field, op: "is EMPTY"}
+}
+
+//field is not EMPTY
+func IsNotEmpty(field string) JQLClause {
+	return &jqlCondition{field: field, op: "is not EMPTY"}
+}
+
+type jqlGroup struct {
+	op      string
+	clauses []JQLClause
+}
+
+func (g *jqlGroup) parts() []string {
+	parts := make([]string, 0, len(g.clauses))
+	for _, c := range g.clauses {
+		if c == nil {
+			continue
+		}
+		s := c.String()
+		if s == "" {
+			continue
+		}
+		if needsParens(c) {
+			s = "(" + s + ")"
+		}
+		parts = append(parts, s)
+	}
+	return parts
+}
+
+//Tells whether a clause must be parenthesized to keep its meaning inside a group.
+//Only conditions, negations and groups of a single clause are safe as they are.
+func needsParens(c JQLClause) bool {
+	switch clause := c.(type) {
+	case *jqlCondition, *jqlNot:
+		return false
+	case *jqlGroup:
+		return len(clause.parts()) > 1
+	}
+	return true
+}
+
+func (g *jqlGroup) String() string {
+	return strings.Join(g.parts(), " "+g.op+" ")
+}
+
+//Clauses joined with AND, grouped with parentheses when nested in another group.
+func And(clauses ...JQLClause) JQLClause {
+	return &jqlGroup{"AND", clauses}
+}
+
+//Clauses joined with OR, grouped with parentheses when nested in another group.
+func Or(clauses ...JQLClause) JQLClause {
+	return &jqlGroup{"OR", clauses}
+}
+
+type jqlNot struct {
+	clause JQLClause
+}
+
+func (n *jqlNot) String() string {
+	s := n.clause.String()
+	if s == "" {
+		return ""
+	}
+	return "NOT (" + s + ")"
+}
+
+//NOT (clause)
+func Not(clause JQLClause) JQLClause {
+	return &jqlNot{clause}
+}
+
+//JQL used verbatim, for constructs the builder doesn't cover. It is parenthesized when nested in And or Or.
+type RawJQL string
+
+func (r RawJQL) String() string {
+	return string(r)
+}
+
+type JQLOrder struct {
+	Field      string
+	Descending bool
+}
+
+//A complete JQL query: a condition followed by an ORDER BY.
+type JQLQuery struct {
+	Where JQLClause
+	Order []JQLOrder
+}
+
+func NewJQL(where ...JQLClause) *JQLQuery {
+	return &JQLQuery{Where: And(where...)}
+}
+
+//Appends a sort key to the query.
+func (q *JQLQuery) OrderBy(field string, descending bool) *JQLQuery {
+	q.Order = append(q.Order, JQLOrder{field, descending})
+	return q
+}
+
+func (q *JQLQuery) String() string {
+	s := ""
+	if q.Where != nil {
+		s = q.Where.String()
+	}
+	if len(q.Order) > 0 {
+		keys := make([]string, 0, len(q.Order))
+		for _, o := range q.Order {
+			dir := "ASC"
+			if o.Descending {
+				dir = "DESC"
+			}
+			keys = append(keys, fmt.Sprintf("%s %s", jqlField(o.Field), dir))
+		}
+		s = strings.TrimSpace(s + " ORDER BY " + strings.Join(keys, ", "))
+	}
+	return s
+}
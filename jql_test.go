@@ -0,0 +1,74 @@
+package libgojira
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJQLClauses(t *testing.T) {
+	for _, test := range []struct {
+		clause JQLClause
+		want   string
+	}{
+		{Eq("summary", `it's a "quote" \ backslash`), `summary = "it's a \"quote\" \\ backslash"`},
+		{Eq("Story Points", 3), `"Story Points" = 3`},
+		{Eq("cf[10003]", 3), `cf[10003] = 3`},
+		{NotEq("status", "Done"), `status != "Done"`},
+		{In("project", "ABC", "DEF"), `project in ("ABC", "DEF")`},
+		{In("project"), ``},
+		{NotIn("status"), ``},
+		{NotIn("status", "Done"), `status not in ("Done")`},
+		{In("sprint", JQLFunc("openSprints()")), `sprint in (openSprints())`},
+		{Contains("text", "crash"), `text ~ "crash"`},
+		{Gte("updated", "-7d"), `updated >= "-7d"`},
+		{Lt("created", time.Date(2024, 1, 2, 15, 4, 0, 0, time.UTC)), `created < "2024/01/02 15:04"`},
+		{IsEmpty("assignee"), `assignee is EMPTY`},
+		{Not(Eq("status", "Done")), `NOT (status = "Done")`},
+		{Not(In("status")), ``},
+		{And(Eq("a", 1), In("b"), nil, Eq("c", 2)), `a = 1 AND c = 2`},
+		{And(Eq("a", 1), Or(Eq("b", 2), Eq("c", 3))), `a = 1 AND (b = 2 OR c = 3)`},
+		{Or(And(Eq("a", 1)), Eq("b", 2)), `a = 1 OR b = 2`},
+		{And(Eq("a", 1), Or(And(Eq("b", 2), Eq("c", 3)), Eq("d", 4))), `a = 1 AND ((b = 2 AND c = 3) OR d = 4)`},
+		{And(Eq("project", "X"), RawJQL("status = A OR status = B")), `project = "X" AND (status = A OR status = B)`},
+		{Or(RawJQL("a = 1"), Not(RawJQL("b = 2"))), `(a = 1) OR NOT (b = 2)`},
+	} {
+		if got := test.clause.String(); got != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+		}
+	}
+}
+
+func TestJQLQuery(t *testing.T) {
+	q := NewJQL(Eq("project", "X"), RawJQL("status = A OR status = B")).OrderBy("rank", false).OrderBy("Story Points", true)
+	want := `project = "X" AND (status = A OR status = B) ORDER BY rank ASC, "Story Points" DESC`
+	if got := q.String(); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got := NewJQL().OrderBy("rank", false).String(); got != "ORDER BY rank ASC" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestBuildQuery(t *testing.T) {
+	for _, test := range []struct {
+		opts *SearchOptions
+		want string
+	}{
+		{&SearchOptions{}, `ORDER BY rank ASC`},
+		{&SearchOptions{Projects: []string{"ABC"}, Open: true}, `status = "open" AND project in ("ABC") ORDER BY rank ASC`},
+		{&SearchOptions{CurrentSprint: true, Issue: "ABC-1", NotType: []string{"Epic"}, Status: []string{"To Do", "In \"Progress\""}},
+			`sprint in (openSprints()) AND (issue = "ABC-1" OR parent = "ABC-1") AND type not in ("Epic") AND status in ("To Do", "In \"Progress\"") ORDER BY rank ASC`},
+	} {
+		if got := test.opts.BuildQuery().String(); got != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+		}
+	}
+	opts := &SearchOptions{JQL: "key = ABC-1", Query: NewJQL(Eq("a", 1)), Projects: []string{"ABC"}}
+	if got := opts.JQLString(); got != "key = ABC-1" {
+		t.Errorf("JQL should have precedence, got %s", got)
+	}
+	opts.JQL = ""
+	if got := opts.JQLString(); got != "a = 1" {
+		t.Errorf("Query should have precedence, got %s", got)
+	}
+}
@@ -0,0 +1,169 @@
+package libgojira
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+//Kind of relation between two issues, described from each side:
+//one issue "blocks" the other, which "is blocked by" it.
+type LinkType struct {
+	Id      string
+	Name    string
+	Inward  string
+	Outward string
+}
+
+//Matches name against the link type's name and descriptions, ignoring case.
+//Returns whether it matched, and whether it designates the inward description.
+func (lt *LinkType) match(name string) (ok bool, inward bool) {
+	switch {
+	case strings.EqualFold(name, lt.Outward), strings.EqualFold(name, lt.Name):
+		return true, false
+	case strings.EqualFold(name, lt.Inward):
+		return true, true
+	}
+	return false, false
+}
+
+//Link between an issue and another, as seen from the former.
+type IssueLink struct {
+	Id          string
+	Type        LinkType
+	Inward      bool   //The other issue is listed as the link's inwardIssue, so this issue "is blocked by" it
+	Description string //Relation of this issue to the other one, e.g. "blocks"
+	IssueKey    string //The other issue
+	Summary     string
+	Status      string
+}
+
+func (il *IssueLink) String() string {
+	return fmt.Sprintf("%s %s (%s)", il.Description, il.IssueKey, il.Status)
+}
+
+func linksFromJSON(links []issueLinkJSON) []*IssueLink {
+	result := []*IssueLink{}
+	for _, l := range links {
+		lt := LinkType(l.Type)
+		link := &IssueLink{Id: l.Id, Type: lt}
+		other := l.OutwardIssue
+		link.Description = lt.Outward
+		if l.InwardIssue != nil {
+			other = l.InwardIssue
+			link.Inward, link.Description = true, lt.Inward
+		}
+		if other == nil {
+			continue
+		}
+		link.IssueKey, link.Summary, link.Status = other.Key, other.Fields.Summary, other.Fields.Status.Name
+		result = append(result, link)
+	}
+	return result
+}
+
+//A link to create, reading "Issue LinkReason LinkedToIssue", such as "ABC-1 blocks ABC-2".
+type Link struct {
+	Issue string
+
+	LinkReason    string //Name of the link type, or its inward or outward description
+	LinkedToIssue string
+	Comment       string
+	TypeId        string //Id of the link type, looked up from LinkReason when empty
+}
+
+//Fetches the link types defined on the instance.
+func (jc *JiraClient) GetLinkTypes() ([]LinkType, error) {
+	return jc.GetLinkTypesContext(context.Background())
+}
+
+func (jc *JiraClient) GetLinkTypesContext(ctx context.Context) ([]LinkType, error) {
+	var types struct {
+		IssueLinkTypes []linkTypeJSON `json:"issueLinkTypes"`
+	}
+	if err := jc.getJSON(ctx, jc.apiUrl("issueLinkType"), &types); err != nil {
+		return nil, err
+	}
+	result := make([]LinkType, 0, len(types.IssueLinkTypes))
+	for _, lt := range types.IssueLinkTypes {
+		result = append(result, LinkType(lt))
+	}
+	return result, nil
+}
+
+//Links two issues, once the link type and its direction have been checked against the server's link types.
+//When LinkReason is the inward description of the type, as in "ABC-2 is blocked by ABC-1",
+//the issues are swapped so the link reads as written.
+func (jc *JiraClient) Link(link *Link) error {
+	return jc.LinkContext(context.Background(), link)
+}
+
+func (jc *JiraClient) LinkContext(ctx context.Context, link *Link) error {
+	types, err := jc.GetLinkTypesContext(ctx)
+	if err != nil {
+		return err
+	}
+	lt, inward, err := findLinkType(types, link)
+	if err != nil {
+		return err
+	}
+	//Jira reads a new link as "inwardIssue <outward description> outwardIssue", e.g. "inwardIssue blocks outwardIssue"
+	inwardKey, outwardKey := link.Issue, link.LinkedToIssue
+	if inward {
+		inwardKey, outwardKey = outwardKey, inwardKey
+	}
+	m := msi{"type": msi{"id": lt.Id}, "inwardIssue": msi{"key": inwardKey}, "outwardIssue": msi{"key": outwardKey}}
+	if link.Comment != "" {
+		m["comment"] = msi{"body": link.Comment}
+	}
+	if err := jc.sendJSON(ctx, "POST", jc.apiUrl("issueLink"), m, nil); err != nil {
+		return err
+	}
+	jc.log().Infof("%s %s %s", link.Issue, link.LinkReason, link.LinkedToIssue)
+	return nil
+}
+
+func findLinkType(types []LinkType, link *Link) (*LinkType, bool, error) {
+	for i := range types {
+		lt := &types[i]
+		if link.TypeId != "" {
+			if lt.Id != link.TypeId {
+				continue
+			}
+			if link.LinkReason == "" {
+				return lt, false, nil
+			}
+		}
+		if ok, inward := lt.match(link.LinkReason); ok {
+			return lt, inward, nil
+		}
+		if link.TypeId != "" {
+			return nil, false, &JiraClientError{fmt.Sprintf("%q doesn't describe link type %s (%s / %s)", link.LinkReason, lt.Name, lt.Outward, lt.Inward)}
+		}
+	}
+	if link.TypeId != "" {
+		return nil, false, fmt.Errorf("link type %s: %w", link.TypeId, ErrNotFound)
+	}
+	reasons := []string{}
+	for _, lt := range types {
+		reasons = append(reasons, lt.Outward, lt.Inward)
+	}
+	return nil, false, &JiraClientError{fmt.Sprintf("Unknown link %q, expected one of: %s", link.LinkReason, strings.Join(reasons, ", "))}
+}
+
+//Deletes a link between two issues given its id, as found in Issue.Links.
+func (jc *JiraClient) DeleteLink(link_id string) error {
+	return jc.DeleteLinkContext(context.Background(), link_id)
+}
+
+func (jc *JiraClient) DeleteLinkContext(ctx context.Context, link_id string) error {
+	lid, err := numOnly(link_id)
+	if err != nil {
+		return &JiraClientError{"Bad link id"}
+	}
+	r, err := jc.DeleteContext(ctx, jc.apiUrl("issueLink/%s", lid), "", nil)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(r, nil)
+}
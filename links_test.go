@@ -0,0 +1,66 @@
+package libgojira
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestLinkPayload(t *testing.T) {
+	for _, tc := range []struct {
+		reason          string
+		inward, outward string
+	}{
+		//Jira creates "ABC-1 blocks ABC-2" from inwardIssue ABC-1 and outwardIssue ABC-2
+		{"blocks", "ABC-1", "ABC-2"},
+		{"Blocks", "ABC-1", "ABC-2"},
+		{"is blocked by", "ABC-2", "ABC-1"},
+	} {
+		var posted struct {
+			Type         struct{ Id string }
+			InwardIssue  struct{ Key string }
+			OutwardIssue struct{ Key string }
+			Comment      struct{ Body string }
+		}
+		jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == "GET" && r.URL.Path == "/jira/rest/api/2/issueLinkType":
+				w.Write([]byte(`{"issueLinkTypes":[{"id":"10000","name":"Blocks","inward":"is blocked by","outward":"blocks"}]}`))
+			case r.Method == "POST" && r.URL.Path == "/jira/rest/api/2/issueLink":
+				if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+					t.Error(err)
+				}
+				w.WriteHeader(http.StatusCreated)
+			default:
+				t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+		if err := jc.Link(&Link{Issue: "ABC-1", LinkReason: tc.reason, LinkedToIssue: "ABC-2", Comment: "Found in review"}); err != nil {
+			t.Fatal(err)
+		}
+		if posted.Type.Id != "10000" || posted.InwardIssue.Key != tc.inward || posted.OutwardIssue.Key != tc.outward || posted.Comment.Body != "Found in review" {
+			t.Errorf("ABC-1 %s ABC-2: posted %+v, want inwardIssue %s and outwardIssue %s", tc.reason, posted, tc.inward, tc.outward)
+		}
+	}
+}
+
+func TestLinksFromJSON(t *testing.T) {
+	var links []issueLinkJSON
+	err := json.Unmarshal([]byte(`[
+		{"id":"1","type":{"id":"10000","name":"Blocks","inward":"is blocked by","outward":"blocks"},"outwardIssue":{"key":"ABC-2","fields":{"summary":"Two","status":{"name":"Open"}}}},
+		{"id":"2","type":{"id":"10000","name":"Blocks","inward":"is blocked by","outward":"blocks"},"inwardIssue":{"key":"ABC-3","fields":{"summary":"Three","status":{"name":"Done"}}}}]`), &links)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := linksFromJSON(links)
+	if len(got) != 2 {
+		t.Fatalf("got %d links, want 2", len(got))
+	}
+	if got[0].Inward || got[0].String() != "blocks ABC-2 (Open)" {
+		t.Errorf("unexpected outward link %+v", got[0])
+	}
+	if !got[1].Inward || got[1].String() != "is blocked by ABC-3 (Done)" {
+		t.Errorf("unexpected inward link %+v", got[1])
+	}
+}
@@ -0,0 +1,48 @@
+package libgojira
+
+import (
+	"log"
+)
+
+//Receives the library's diagnostic output.
+//Nothing is written anywhere unless a Logger is set on the JiraClient.
+type Logger interface {
+	Debugf(format string, v ...interface{}) //Request/response tracing and other verbose output
+	Infof(format string, v ...interface{})  //Outcome of operations, e.g. "PROJ-1 updated"
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debugf(format string, v ...interface{}) {}
+func (nopLogger) Infof(format string, v ...interface{})  {}
+
+//Logger writing to a standard *log.Logger. Debug output is only written when Debug is set.
+type StdLogger struct {
+	Logger *log.Logger
+	Debug  bool
+}
+
+func NewStdLogger(l *log.Logger, debug bool) *StdLogger {
+	return &StdLogger{l, debug}
+}
+
+func (sl *StdLogger) Debugf(format string, v ...interface{}) {
+	if sl.Debug {
+		sl.Logger.Printf(format, v...)
+	}
+}
+
+func (sl *StdLogger) Infof(format string, v ...interface{}) {
+	sl.Logger.Printf(format, v...)
+}
+
+func (jc *JiraClient) SetLogger(l Logger) {
+	jc.Logger = l
+}
+
+func (jc *JiraClient) log() Logger {
+	if jc.Logger == nil {
+		return nopLogger{}
+	}
+	return jc.Logger
+}
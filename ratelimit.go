@@ -0,0 +1,84 @@
+package libgojira
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+//Token bucket limiting the rate and concurrency of the requests sent by a JiraClient.
+//Every attempt of every request goes through it, including retries.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 //Tokens added per second
+	burst  float64
+	tokens float64
+	last   time.Time
+	slots  chan struct{} //Concurrency slots, nil when unbounded
+}
+
+//Returns a limiter allowing rate requests per second on average with bursts of up to burst requests,
+//and at most maxInFlight requests awaiting a response at once.
+//A zero rate or maxInFlight leaves that dimension unbounded.
+func NewRateLimiter(rate float64, burst, maxInFlight int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	rl := &RateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+	if maxInFlight > 0 {
+		rl.slots = make(chan struct{}, maxInFlight)
+	}
+	return rl
+}
+
+//Blocks until a request may be sent. The returned function releases the request's concurrency slot
+//and must be called once its response headers have been received.
+func (rl *RateLimiter) acquire(ctx context.Context) (func(), error) {
+	if rl == nil {
+		return func() {}, nil
+	}
+	if err := sleepContext(ctx, rl.reserve()); err != nil {
+		rl.cancel()
+		return nil, err
+	}
+	if rl.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case rl.slots <- struct{}{}:
+		return func() { <-rl.slots }, nil
+	case <-ctx.Done():
+		rl.cancel()
+		return nil, ctx.Err()
+	}
+}
+
+//Takes a token from the bucket, returning how long to wait before it is actually available.
+func (rl *RateLimiter) reserve() time.Duration {
+	if rl.rate <= 0 {
+		return 0
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	now := time.Now()
+	rl.tokens += now.Sub(rl.last).Seconds() * rl.rate
+	if rl.tokens > rl.burst {
+		rl.tokens = rl.burst
+	}
+	rl.last = now
+	rl.tokens--
+	if rl.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-rl.tokens / rl.rate * float64(time.Second))
+}
+
+//Gives back a token reserved by a request that was abandoned.
+func (rl *RateLimiter) cancel() {
+	if rl.rate <= 0 {
+		return
+	}
+	rl.mu.Lock()
+	rl.tokens++
+	rl.mu.Unlock()
+}
@@ -0,0 +1,87 @@
+package libgojira
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurst(t *testing.T) {
+	rl := NewRateLimiter(10, 3, 0)
+	for i := 0; i < 3; i++ {
+		if d := rl.reserve(); d != 0 {
+			t.Errorf("request %d of the burst waits %s", i+1, d)
+		}
+	}
+	if d := rl.reserve(); d < 90*time.Millisecond || d > 100*time.Millisecond {
+		t.Errorf("request after the burst waits %s, want 100ms", d)
+	}
+}
+
+func TestRateLimiterRate(t *testing.T) {
+	rl := NewRateLimiter(50, 1, 0)
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		release, err := rl.acquire(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	//The first request uses the burst, the 5 others wait 20ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("6 requests at 50/s took %s, want at least 100ms", elapsed)
+	}
+}
+
+func TestRateLimiterMaxInFlight(t *testing.T) {
+	var inFlight, most int32
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			m := atomic.LoadInt32(&most)
+			if n <= m || atomic.CompareAndSwapInt32(&most, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.Write([]byte(testIssue))
+	})
+	jc.Limiter = NewRateLimiter(0, 0, 2)
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := jc.GetIssue("ABC-1"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if most != 2 {
+		t.Errorf("%d requests in flight at most, want 2", most)
+	}
+}
+
+func TestRateLimiterCancelledWaitingForSlot(t *testing.T) {
+	//A token every 1000s, so only the burst is available during the test
+	rl := NewRateLimiter(0.001, 2, 1)
+	release, err := rl.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := rl.acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("got %v waiting for a slot, want %v", err, context.DeadlineExceeded)
+	}
+	release()
+	if d := rl.reserve(); d != 0 {
+		t.Errorf("abandoned request kept its token: next request waits %s", d)
+	}
+}
@@ -0,0 +1,145 @@
+package libgojira
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+//Link from an issue to a web page, such as a build or a document.
+type RemoteLink struct {
+	Id           string
+	GlobalId     string //Identifies the page across issues; adding a link with the GlobalId of an existing one updates it
+	Relationship string //Heading the link is listed under, such as "builds"
+	Url          string
+	Title        string
+	Summary      string
+	IconUrl      string //16x16 icon shown next to the title
+	IconTitle    string
+	Resolved     bool //Shows the link struck through, e.g. for a build that's obsolete
+}
+
+type remoteLinkJSON struct {
+	Id           int64  `json:"id,omitempty"`
+	GlobalId     string `json:"globalId,omitempty"`
+	Relationship string `json:"relationship,omitempty"`
+	Object       struct {
+		Url     string `json:"url"`
+		Title   string `json:"title"`
+		Summary string `json:"summary,omitempty"`
+		Icon    struct {
+			Url16x16 string `json:"url16x16,omitempty"`
+			Title    string `json:"title,omitempty"`
+		} `json:"icon"`
+		Status struct {
+			Resolved bool `json:"resolved"`
+		} `json:"status"`
+	} `json:"object"`
+}
+
+func remoteLinkFromJSON(rl *remoteLinkJSON) *RemoteLink {
+	return &RemoteLink{
+		Id:           strconv.FormatInt(rl.Id, 10),
+		GlobalId:     rl.GlobalId,
+		Relationship: rl.Relationship,
+		Url:          rl.Object.Url,
+		Title:        rl.Object.Title,
+		Summary:      rl.Object.Summary,
+		IconUrl:      rl.Object.Icon.Url16x16,
+		IconTitle:    rl.Object.Icon.Title,
+		Resolved:     rl.Object.Status.Resolved,
+	}
+}
+
+func (rl *RemoteLink) payload() *remoteLinkJSON {
+	m := &remoteLinkJSON{GlobalId: rl.GlobalId, Relationship: rl.Relationship}
+	m.Object.Url, m.Object.Title, m.Object.Summary = rl.Url, rl.Title, rl.Summary
+	m.Object.Icon.Url16x16, m.Object.Icon.Title = rl.IconUrl, rl.IconTitle
+	m.Object.Status.Resolved = rl.Resolved
+	return m
+}
+
+//Fetches the remote links of an issue.
+func (jc *JiraClient) GetRemoteLinks(issueKey string) ([]*RemoteLink, error) {
+	return jc.GetRemoteLinksContext(context.Background(), issueKey)
+}
+
+func (jc *JiraClient) GetRemoteLinksContext(ctx context.Context, issueKey string) ([]*RemoteLink, error) {
+	var links []remoteLinkJSON
+	if err := jc.getJSON(ctx, jc.apiUrl("issue/%s/remotelink", issueKey), &links); err != nil {
+		return nil, err
+	}
+	result := make([]*RemoteLink, 0, len(links))
+	for i := range links {
+		result = append(result, remoteLinkFromJSON(&links[i]))
+	}
+	return result, nil
+}
+
+//Adds a remote link to an issue, or updates the one with the same GlobalId.
+//Returns a copy of the link with its Id set.
+func (jc *JiraClient) AddRemoteLink(issueKey string, link *RemoteLink) (*RemoteLink, error) {
+	return jc.AddRemoteLinkContext(context.Background(), issueKey, link)
+}
+
+func (jc *JiraClient) AddRemoteLinkContext(ctx context.Context, issueKey string, link *RemoteLink) (*RemoteLink, error) {
+	if link.Url == "" || link.Title == "" {
+		return nil, &JiraClientError{"A remote link needs a url and a title"}
+	}
+	var created struct {
+		Id int64 `json:"id"`
+	}
+	if err := jc.sendJSON(ctx, "POST", jc.apiUrl("issue/%s/remotelink", issueKey), link.payload(), &created); err != nil {
+		return nil, err
+	}
+	result := *link
+	result.Id = strconv.FormatInt(created.Id, 10)
+	jc.log().Infof("%s linked to %s", link.Url, issueKey)
+	return &result, nil
+}
+
+//Deletes a remote link of an issue given its id.
+func (jc *JiraClient) DeleteRemoteLink(issueKey string, link_id string) error {
+	return jc.DeleteRemoteLinkContext(context.Background(), issueKey, link_id)
+}
+
+func (jc *JiraClient) DeleteRemoteLinkContext(ctx context.Context, issueKey string, link_id string) error {
+	return jc.delById(ctx, "remotelink", issueKey, link_id)
+}
+
+//Deletes the remote link of an issue with the given GlobalId.
+func (jc *JiraClient) DeleteRemoteLinkByGlobalId(issueKey string, globalId string) error {
+	return jc.DeleteRemoteLinkByGlobalIdContext(context.Background(), issueKey, globalId)
+}
+
+func (jc *JiraClient) DeleteRemoteLinkByGlobalIdContext(ctx context.Context, issueKey string, globalId string) error {
+	r, err := jc.DeleteContext(ctx, jc.apiUrl("issue/%s/remotelink?%s", issueKey, url.Values{"globalId": {globalId}}.Encode()), "", nil)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(r, nil)
+}
+
+func (i *Issue) GetRemoteLinks(jc *JiraClient) ([]*RemoteLink, error) {
+	return jc.GetRemoteLinks(i.Key)
+}
+
+func (i *Issue) GetRemoteLinksContext(ctx context.Context, jc *JiraClient) ([]*RemoteLink, error) {
+	return jc.GetRemoteLinksContext(ctx, i.Key)
+}
+
+func (i *Issue) AddRemoteLink(jc *JiraClient, link *RemoteLink) (*RemoteLink, error) {
+	return jc.AddRemoteLink(i.Key, link)
+}
+
+func (i *Issue) AddRemoteLinkContext(ctx context.Context, jc *JiraClient, link *RemoteLink) (*RemoteLink, error) {
+	return jc.AddRemoteLinkContext(ctx, i.Key, link)
+}
+
+func (i *Issue) DeleteRemoteLink(jc *JiraClient, link_id string) error {
+	return jc.DeleteRemoteLink(i.Key, link_id)
+}
+
+func (i *Issue) DeleteRemoteLinkContext(ctx context.Context, jc *JiraClient, link_id string) error {
+	return jc.DeleteRemoteLinkContext(ctx, i.Key, link_id)
+}
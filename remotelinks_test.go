@@ -0,0 +1,72 @@
+package libgojira
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestAddRemoteLinkPayload(t *testing.T) {
+	var posted map[string]interface{}
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/jira/rest/api/2/issue/ABC-1/remotelink" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":10000,"self":"http://example.com/jira/rest/api/2/issue/ABC-1/remotelink/10000"}`))
+	})
+	link := &RemoteLink{GlobalId: "build=42", Relationship: "builds", Url: "https://ci.example.com/42", Title: "Build 42",
+		Summary: "Passed", IconUrl: "https://ci.example.com/icon.png", IconTitle: "CI", Resolved: true}
+	created, err := jc.AddRemoteLink("ABC-1", link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Id != "10000" || link.Id != "" {
+		t.Errorf("got id %q, want 10000 on a copy of the link", created.Id)
+	}
+	want := `{"globalId":"build=42","object":{"icon":{"title":"CI","url16x16":"https://ci.example.com/icon.png"},` +
+		`"status":{"resolved":true},"summary":"Passed","title":"Build 42","url":"https://ci.example.com/42"},"relationship":"builds"}`
+	if got, _ := json.Marshal(posted); string(got) != want {
+		t.Errorf("posted %s, want %s", got, want)
+	}
+	if _, err := jc.AddRemoteLink("ABC-1", &RemoteLink{Title: "No url"}); err == nil {
+		t.Error("no error for a link without url")
+	}
+}
+
+func TestGetRemoteLinks(t *testing.T) {
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":10000,"globalId":"build=42","relationship":"builds","object":{"url":"https://ci.example.com/42","title":"Build 42",` +
+			`"icon":{"url16x16":"https://ci.example.com/icon.png","title":"CI"},"status":{"resolved":true}}}]`))
+	})
+	links, err := jc.GetRemoteLinks("ABC-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := RemoteLink{Id: "10000", GlobalId: "build=42", Relationship: "builds", Url: "https://ci.example.com/42", Title: "Build 42",
+		IconUrl: "https://ci.example.com/icon.png", IconTitle: "CI", Resolved: true}
+	if len(links) != 1 || *links[0] != want {
+		t.Errorf("got %+v, want %+v", links, want)
+	}
+}
+
+func TestDeleteRemoteLinkByGlobalId(t *testing.T) {
+	var globalId, rawQuery string
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" || r.URL.Path != "/jira/rest/api/2/issue/ABC-1/remotelink" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		globalId, rawQuery = r.URL.Query().Get("globalId"), r.URL.RawQuery
+		w.WriteHeader(http.StatusNoContent)
+	})
+	id := "system=https://ci.example.com/job?a=1&b=2#frag"
+	if err := jc.DeleteRemoteLinkByGlobalId("ABC-1", id); err != nil {
+		t.Fatal(err)
+	}
+	if globalId != id {
+		t.Errorf("got globalId %q (query %s), want %q", globalId, rawQuery, id)
+	}
+}
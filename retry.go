@@ -0,0 +1,132 @@
+package libgojira
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//Controls how requests failing with a transient error (network error, 429, 502, 503 or 504) are retried.
+//GET, PUT and DELETE requests are retried; POST requests only when RetryPost is set,
+//since Jira doesn't treat them as idempotent. Requests whose body can't be replayed are never retried.
+type RetryPolicy struct {
+	MaxAttempts int           //Total attempts, including the first one
+	MinBackoff  time.Duration //Delay before the first retry, doubled on every attempt
+	MaxBackoff  time.Duration //Upper bound on the delay between attempts; a server asking to wait longer gets no retry
+	Jitter      float64       //Fraction of the delay that is randomized, between 0 and 1
+	RetryPost   bool          //Also retry POST requests
+}
+
+//Policy installed by NewJiraClient.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  30 * time.Second,
+	Jitter:      0.2,
+}
+
+func (rp *RetryPolicy) canRetry(req *http.Request, attempt int) bool {
+	if rp == nil || attempt >= rp.MaxAttempts {
+		return false
+	}
+	if req.Method == "POST" && !rp.RetryPost {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func isTransient(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+//Returns how long to wait before the next attempt, honoring the server's Retry-After
+//and X-RateLimit-Reset headers when present, and whether that delay is within MaxBackoff.
+func (rp *RetryPolicy) delay(resp *http.Response, attempt int) (time.Duration, bool) {
+	if resp != nil {
+		if d, ok := serverDelay(resp.Header, time.Now()); ok {
+			return d, rp.MaxBackoff <= 0 || d <= rp.MaxBackoff
+		}
+	}
+	backoff := float64(rp.MinBackoff) * math.Pow(2, float64(attempt-1))
+	if rp.MaxBackoff > 0 && backoff > float64(rp.MaxBackoff) {
+		backoff = float64(rp.MaxBackoff)
+	}
+	if rp.Jitter > 0 {
+		backoff += backoff * rp.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(backoff), true
+}
+
+func serverDelay(h http.Header, now time.Time) (time.Duration, bool) {
+	if ra := h.Get("Retry-After"); ra != "" {
+		if secs, err := strconv.Atoi(ra); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(ra); err == nil {
+			return positive(t.Sub(now)), true
+		}
+	}
+	if h.Get("X-RateLimit-Remaining") == "0" {
+		reset := h.Get("X-RateLimit-Reset")
+		if t, err := time.Parse(time.RFC3339, reset); err == nil {
+			return positive(t.Sub(now)), true
+		}
+		if secs, err := strconv.ParseInt(reset, 10, 64); err == nil {
+			return positive(time.Unix(secs, 0).Sub(now)), true
+		}
+	}
+	return 0, false
+}
+
+func positive(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+//Waits for d, returning early with the context's error if it is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+//Prepares a copy of the request for another attempt, with a fresh body and authentication.
+func (jc *JiraClient) rewind(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	if jc.OAuthCfg != nil {
+		retry.Header.Del("Authorization")
+		jc.OAuthService.Sign(retry, jc.OAuthCfg)
+	}
+	return retry, nil
+}
+
+func drain(resp *http.Response) {
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+}
@@ -0,0 +1,114 @@
+package libgojira
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Second}
+}
+
+func TestRetryGetOn429(t *testing.T) {
+	var attempts int32
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(testIssue))
+	})
+	jc.Retry = testRetryPolicy()
+	if _, err := jc.GetIssue("ABC-1"); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 {
+		t.Errorf("got %d attempts, want 3", attempts)
+	}
+}
+
+func TestRetryStopsAtMaxAttempts(t *testing.T) {
+	var attempts int32
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	jc.Retry = testRetryPolicy()
+	_, err := jc.GetIssue("ABC-1")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got error %v, want a 503 APIError", err)
+	}
+	if int(attempts) != jc.Retry.MaxAttempts {
+		t.Errorf("got %d attempts, want %d", attempts, jc.Retry.MaxAttempts)
+	}
+}
+
+func TestRetryPost(t *testing.T) {
+	for _, retryPost := range []bool{false, true} {
+		var attempts int32
+		jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+		jc.Retry = testRetryPolicy()
+		jc.Retry.RetryPost = retryPost
+		resp, err := jc.Post(jc.apiUrl("issue"), "application/json", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		want := int32(1)
+		if retryPost {
+			want = int32(jc.Retry.MaxAttempts)
+		}
+		if attempts != want {
+			t.Errorf("RetryPost %v: got %d attempts, want %d", retryPost, attempts, want)
+		}
+	}
+}
+
+func TestRetryRespectsMaxBackoff(t *testing.T) {
+	var attempts int32
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	jc.Retry = testRetryPolicy()
+	start := time.Now()
+	_, err := jc.GetIssue("ABC-1")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("got error %v, want a 429 APIError", err)
+	}
+	if attempts != 1 || time.Since(start) > time.Second {
+		t.Errorf("got %d attempts in %s, want 1 without waiting", attempts, time.Since(start))
+	}
+}
+
+func TestRetryCancelledDuringBackoff(t *testing.T) {
+	var attempts int32
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	jc.Retry = testRetryPolicy()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := jc.GetIssueContext(ctx, "ABC-1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want context.DeadlineExceeded", err)
+	}
+	if attempts != 1 || time.Since(start) > time.Second {
+		t.Errorf("got %d attempts in %s, want 1 interrupted backoff", attempts, time.Since(start))
+	}
+}
@@ -0,0 +1,185 @@
+package libgojira
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+//Represents search options to Jira
+type SearchOptions struct {
+	Projects      []string  //Limit search to a specific project
+	CurrentSprint bool      //Limit search to stories in current sprint
+	Open          bool      //Limit search to open issues
+	Issue         string    //Limit search to a single issue
+	JQL           string    //Pure JQL query, has precedence over any other option
+	Query         *JQLQuery //Built JQL query, has precedence over the criteria below
+	Type          []string
+	NotType       []string
+	Status        []string
+	NotStatus     []string
+	PageSize      int      //Issues fetched per request, Jira's default when 0
+	Limit         int      //Maximum number of issues returned, unbounded when 0
+	Fields        []string //Fields to fetch, all of them when empty
+	Expand        []string //Extra information to fetch: changelog, renderedFields, names, transitions...
+}
+
+//Returns the JQL query described by the options.
+//JQL has precedence over Query, which has precedence over the other criteria.
+func (searchoptions *SearchOptions) JQLString() string {
+	if searchoptions.JQL != "" {
+		return searchoptions.JQL
+	}
+	if searchoptions.Query != nil {
+		return searchoptions.Query.String()
+	}
+	return searchoptions.BuildQuery().String()
+}
+
+//Compiles the search criteria into a JQL query ordered by rank.
+func (searchoptions *SearchOptions) BuildQuery() *JQLQuery {
+	clauses := []JQLClause{}
+	if searchoptions.CurrentSprint {
+		clauses = append(clauses, In("sprint", JQLFunc("openSprints()")))
+	}
+	if searchoptions.Open {
+		clauses = append(clauses, Eq("status", "open"))
+	}
+	if searchoptions.Issue != "" {
+		clauses = append(clauses, Or(Eq("issue", searchoptions.Issue), Eq("parent", searchoptions.Issue)))
+	}
+	clauses = append(clauses,
+		In("project", stringValues(searchoptions.Projects)...),
+		In("type", stringValues(searchoptions.Type)...),
+		NotIn("type", stringValues(searchoptions.NotType)...),
+		In("status", stringValues(searchoptions.Status)...),
+		NotIn("status", stringValues(searchoptions.NotStatus)...))
+	return NewJQL(clauses...).OrderBy("rank", false)
+}
+
+func stringValues(s []string) []interface{} {
+	values := make([]interface{}, 0, len(s))
+	for _, v := range s {
+		values = append(values, v)
+	}
+	return values
+}
+
+func (ja *JiraClient) Search(searchoptions *SearchOptions) ([]*Issue, error) {
+	return ja.SearchContext(context.Background(), searchoptions)
+}
+
+func (ja *JiraClient) SearchContext(ctx context.Context, searchoptions *SearchOptions) ([]*Issue, error) {
+	result := []*Issue{}
+	it := ja.SearchIter(ctx, searchoptions)
+	for it.Next() {
+		result = append(result, it.Issue())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+//Iterates over the results of a search, fetching a page of issues at a time.
+//Issues that can't be decoded are skipped; any other failure, including those of the requests
+//made to complete an issue, stops the iteration. Typical usage:
+//
+//	it := jc.SearchIter(ctx, opts)
+//	for it.Next() {
+//		issue := it.Issue()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type SearchIterator struct {
+	jc       *JiraClient
+	ctx      context.Context
+	params   url.Values
+	pageSize int
+	limit    int
+	startAt  int
+	lastPage bool
+	page     []json.RawMessage
+	names    map[string]string
+	returned int
+	issue    *Issue
+	err      error
+}
+
+func (ja *JiraClient) SearchIter(ctx context.Context, searchoptions *SearchOptions) *SearchIterator {
+	it := &SearchIterator{
+		jc:       ja,
+		ctx:      ctx,
+		params:   (&GetIssueOptions{Fields: searchoptions.Fields, Expand: searchoptions.Expand}).params(),
+		pageSize: searchoptions.PageSize,
+		limit:    searchoptions.Limit,
+	}
+	it.params.Set("jql", searchoptions.JQLString())
+	if len(searchoptions.Fields) == 0 {
+		it.params.Set("fields", "*all")
+	}
+	return it
+}
+
+//Advances to the next issue, returning false when the results are exhausted or an error occurred.
+func (it *SearchIterator) Next() bool {
+	it.issue = nil
+	for it.err == nil && (it.limit <= 0 || it.returned < it.limit) {
+		if len(it.page) == 0 {
+			if it.lastPage {
+				return false
+			}
+			it.fetch()
+			continue
+		}
+		raw := it.page[0]
+		it.page = it.page[1:]
+		var ij issueJSON
+		if err := json.Unmarshal(raw, &ij); err != nil {
+			it.jc.log().Debugf("Skipping issue: %s", err)
+			continue
+		}
+		if ij.Names == nil {
+			ij.Names = it.names
+		}
+		iss, err := it.jc.newIssue(it.ctx, &ij)
+		if _, malformed := err.(*IssueError); malformed {
+			it.jc.log().Debugf("Skipping issue: %s", err)
+			continue
+		}
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.issue = iss
+		it.returned++
+		return true
+	}
+	return false
+}
+
+func (it *SearchIterator) fetch() {
+	it.params.Set("startAt", strconv.Itoa(it.startAt))
+	if it.pageSize > 0 {
+		it.params.Set("maxResults", strconv.Itoa(it.pageSize))
+	}
+	var page searchResultJSON
+	if it.err = it.jc.getJSON(it.ctx, it.jc.apiUrl("search?%s", it.params.Encode()), &page); it.err != nil {
+		return
+	}
+	it.page = page.Issues
+	it.names = page.Names
+	it.startAt = page.StartAt + len(page.Issues)
+	it.lastPage = len(page.Issues) == 0 || it.startAt >= page.Total
+}
+
+//Returns the issue reached by the last call to Next.
+func (it *SearchIterator) Issue() *Issue {
+	return it.issue
+}
+
+//Returns the error that stopped the iteration, if any.
+func (it *SearchIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,58 @@
+package libgojira
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSearchSkipsMalformedIssues(t *testing.T) {
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"startAt":0,"maxResults":50,"total":3,"issues":[` + testIssue + `,{"id":"2"},{"key":"ABC-3","fields":"oops"}]}`))
+	})
+	issues, err := jc.Search(&SearchOptions{JQL: "project = ABC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Key != "ABC-1" {
+		t.Errorf("unexpected issues %v", issues)
+	}
+}
+
+func TestSearchFailsOnIncompleteIssues(t *testing.T) {
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/search") {
+			w.Write([]byte(`{"startAt":0,"maxResults":50,"total":1,"issues":[{"key":"ABC-1","fields":{"subtasks":[{"key":"ABC-2"}]}}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	jc.Retry = nil
+	jc.options.IncludeSubtasks = true
+	issues, err := jc.Search(&SearchOptions{JQL: "project = ABC"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got %v, %v, want a 503 APIError", issues, err)
+	}
+}
+
+func TestSearchPages(t *testing.T) {
+	var starts []string
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		start := r.URL.Query().Get("startAt")
+		starts = append(starts, start)
+		if start == "0" {
+			w.Write([]byte(`{"startAt":0,"maxResults":1,"total":2,"issues":[` + testIssue + `]}`))
+			return
+		}
+		w.Write([]byte(`{"startAt":1,"maxResults":1,"total":2,"issues":[` + strings.Replace(testIssue, "ABC-1", "ABC-2", 1) + `]}`))
+	})
+	issues, err := jc.Search(&SearchOptions{JQL: "project = ABC", PageSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 || strings.Join(starts, ",") != "0,1" {
+		t.Errorf("got %d issues from pages %v", len(issues), starts)
+	}
+}
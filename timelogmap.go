@@ -6,25 +6,45 @@ import (
 	"fmt"
 
 	"sort"
+	"strings"
 	"text/template"
 	"time"
 )
 
+//A worklog. Its JSON representation refers to the issue by key and
+//spells dates in RFC 3339, so it can be cached and reloaded.
 type TimeLog struct {
-	Key     string
-	LogId   string
-	Date    time.Time
-	Seconds int
-	Issue   *Issue
-	Author  string
+	Key             string    `json:"key"`
+	IssueId         string    `json:"issueId,omitempty"`
+	LogId           string    `json:"logId,omitempty"`
+	Date            time.Time `json:"date"`    //Midnight of the day the work was started, in the time zone logs are bucketed in
+	Started         time.Time `json:"started"` //Precise start of the work, as sent by Jira
+	Seconds         int       `json:"seconds"`
+	Issue           *Issue    `json:"-"`
+	Author          string    `json:"author"`                    //Login of the author, empty on Jira Cloud
+	AuthorAccountId string    `json:"authorAccountId,omitempty"` //Identifies the author on Jira Cloud
+	AuthorEmail     string    `json:"authorEmail,omitempty"`     //Only sent when the author's email is visible to the client's user
+	AuthorName      string    `json:"authorName,omitempty"`      //Display name of the author
+	Comment         string    `json:"comment,omitempty"`
 }
 
 func (tl TimeLog) String() string {
 	return fmt.Sprintf("%s : %s", tl.Key, tl.PrettySeconds())
 }
 
+//Formats the time spent in Jira syntax, converting days and weeks with conf, DefaultTimeTrackingConfig when nil.
+func (tl TimeLog) FormatDuration(conf *TimeTrackingConfig) string {
+	return conf.orDefault().FormatDuration(time.Duration(tl.Seconds) * time.Second)
+}
+
+//Whether the log was written by user, given as a login, an account id or an email address.
+func (tl TimeLog) By(user string) bool {
+	return user != "" && (user == tl.Author || user == tl.AuthorAccountId || strings.EqualFold(user, tl.AuthorEmail))
+}
+
+//Formats the time spent in Jira syntax with DefaultTimeTrackingConfig.
 func (tl TimeLog) PrettySeconds() string {
-	return PrettySeconds(tl.Seconds)
+	return tl.FormatDuration(nil)
 }
 
 func (tl TimeLog) Sprintf(format string) (string, error) {
@@ -38,6 +58,8 @@ func (tl TimeLog) Sprintf(format string) (string, error) {
 	return txtbuff.String(), nil
 }
 
+//Formats seconds as hours, minutes and seconds.
+//Deprecated: use FormatJiraDuration or TimeTrackingConfig.FormatDuration, which also count days and weeks.
 func PrettySeconds(seconds int) string {
 	//This works because it's an integer division.
 	hours := seconds / 3600
@@ -48,19 +70,24 @@ func PrettySeconds(seconds int) string {
 }
 
 func (tl TimeLog) Percentage() string {
-	if tl.Issue.OriginalEstimate == 0 {
+	if tl.Issue == nil || tl.Issue.OriginalEstimate == 0 {
 		return "N/A"
 	}
 	return fmt.Sprintf("%2.2f%%", (tl.Issue.TimeSpent/tl.Issue.OriginalEstimate)*100)
 }
 
 func (tlm TimeLogMap) String() string {
+	return tlm.SprintWith(nil)
+}
+
+//Lists the logs per day, converting durations to days and weeks with conf, DefaultTimeTrackingConfig when nil.
+func (tlm TimeLogMap) SprintWith(conf *TimeTrackingConfig) string {
 	buf := bytes.NewBuffer([]byte{})
 	keys := tlm.GetSortedKeys()
 	for _, k := range keys {
 		buf.WriteString(fmt.Sprintf("  %v\n", k))
 		for _, timelog := range tlm[k] {
-			buf.WriteString(fmt.Sprintf("    %v (# %s by %s) \n", timelog.PrettySeconds(), timelog.LogId, timelog.Author))
+			buf.WriteString(fmt.Sprintf("    %v (# %s by %s) \n", timelog.FormatDuration(conf), timelog.LogId, timelog.Author))
 		}
 	}
 	return buf.String()
@@ -68,16 +95,59 @@ func (tlm TimeLogMap) String() string {
 
 type TimeLogMap map[time.Time][]TimeLog
 
+//Encodes the map as an object keyed by the RFC 3339 representation of the days.
 func (tlm TimeLogMap) MarshalJSON() ([]byte, error) {
-	mst := map[string]string{}
+	m := make(map[string][]TimeLog, len(tlm))
 	for k, v := range tlm {
-		b, err := json.Marshal(v)
+		m[k.Format(time.RFC3339)] = v
+	}
+	return json.Marshal(m)
+}
+
+//Decodes a map encoded by MarshalJSON, keeping its days. Days are time.Time keys, which only match keys
+//in the same *time.Location, and decoded days are in fixed zones: use UnmarshalTimeLogMap to look them up
+//with days in the location the map was built in.
+func (tlm *TimeLogMap) UnmarshalJSON(b []byte) error {
+	var m map[string][]TimeLog
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	*tlm = make(TimeLogMap, len(m))
+	for k, v := range m {
+		day, err := time.Parse(time.RFC3339, k)
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("bad day %q: %w", k, err)
+		}
+		for i := range v {
+			v[i].Date = day
+			if v[i].Started.IsZero() {
+				v[i].Started = day
+			}
+		}
+		(*tlm)[day] = v
+	}
+	return nil
+}
+
+//Decodes a map encoded by MarshalJSON, keyed by days in loc, such as the JiraClient.Location it was built with.
+func UnmarshalTimeLogMap(b []byte, loc *time.Location) (TimeLogMap, error) {
+	var tlm TimeLogMap
+	if err := json.Unmarshal(b, &tlm); err != nil {
+		return nil, err
+	}
+	return tlm.In(loc), nil
+}
+
+//Returns the logs bucketed by the day they were started in loc.
+func (tlm TimeLogMap) In(loc *time.Location) TimeLogMap {
+	result := TimeLogMap{}
+	for _, logs := range tlm {
+		for _, tl := range logs {
+			tl.Date = startOfDay(tl.Started, loc)
+			result[tl.Date] = append(result[tl.Date], tl)
 		}
-		mst[fmt.Sprintf("%d", k.Unix())] = string(b)
 	}
-	return json.Marshal(mst)
+	return result
 }
 
 type TimeSlice []time.Time
@@ -119,36 +189,38 @@ func (tlm TimeLogMap) SumForMap() int {
 	return seconds
 }
 
-func TimeLogForIssue(issue *Issue, issue_json interface{}) TimeLogMap {
+//Returns the worklogs embedded in an issue's JSON, bucketed by the day they were started in loc.
+func TimeLogForIssue(issue *Issue, issue_json interface{}, loc *time.Location) (TimeLogMap, error) {
+	var ij issueJSON
+	if err := fromIface(issue_json, &ij); err != nil {
+		return nil, err
+	}
+	return timeLogFromJSON(issue, ij.Fields.Worklog.Worklogs, loc)
+}
+
+func timeLogFromJSON(issue *Issue, logs []worklogJSON, loc *time.Location) (TimeLogMap, error) {
 	logs_for_times := TimeLogMap{}
-	logs_json, _ := jsonWalker("fields/worklog/worklogs", issue_json)
-	logs, ok := logs_json.([]interface{})
-	if ok {
-		for _, log := range logs {
-			//We got good json and it's by our user
-			authorjson, _ := jsonWalker("author/name", log)
-			logidjson, _ := jsonWalker("id", log)
-			logid, _ := logidjson.(string)
-			if author, ok := authorjson.(string); ok {
-				dsjson, _ := jsonWalker("started", log)
-				if date_string, ok := dsjson.(string); ok {
-					//"2013-11-08T11:37:03.000-0500" <-- date format
-					precise_time, _ := time.Parse(JIRA_TIME_FORMAT, date_string)
-
-					date := time.Date(precise_time.Year(), precise_time.Month(), precise_time.Day(), 0, 0, 0, 0, precise_time.Location())
-					secondsjson, _ := jsonWalker("timeSpentSeconds", log)
-					seconds := int(secondsjson.(float64))
-					if _, ok := logs_for_times[date]; !ok {
-						logs_for_times[date] = make([]TimeLog, 0)
-					}
-					logs_for_times[date] = append(logs_for_times[date], TimeLog{issue.Key, logid, date, seconds, issue, author})
-
-				}
-			}
+	for _, log := range logs {
+		if log.Author.Name == "" && log.Author.AccountId == "" {
+			continue
+		}
+		if log.Started.IsZero() {
+			return nil, fmt.Errorf("worklog %s on %s has no start time", log.Id, issue.Key)
 		}
-		return logs_for_times
+		tl := timeLogFromWorklog(issue.Key, issue, &log, loc)
+		logs_for_times[tl.Date] = append(logs_for_times[tl.Date], tl)
 	}
-	return nil
+	return logs_for_times, nil
+}
+
+//Converts a worklog payload, bucketing it on the day it was started in loc.
+func timeLogFromWorklog(key string, issue *Issue, log *worklogJSON, loc *time.Location) TimeLog {
+	return TimeLog{Key: key, IssueId: log.IssueId, LogId: log.Id, Date: startOfDay(log.Started.Time, loc), Started: log.Started.Time, Seconds: log.TimeSpentSeconds, Issue: issue, Author: log.Author.Name, AuthorAccountId: log.Author.AccountId, AuthorEmail: log.Author.EmailAddress, AuthorName: log.Author.DisplayName, Comment: log.Comment}
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
 }
 
 const JIRA_TIME_FORMAT = "2006-01-02T15:04:05.000-0700"
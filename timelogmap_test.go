@@ -0,0 +1,49 @@
+package libgojira
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimeLogMapJSON(t *testing.T) {
+	montreal, err := time.LoadLocation("America/Montreal")
+	if err != nil {
+		t.Skip(err)
+	}
+	//Started late on the 2nd in Montreal, already the 3rd in UTC
+	started := time.Date(2024, 1, 2, 22, 30, 0, 0, montreal)
+	logs := []worklogJSON{{Id: "1", Author: userJSON{Name: "bob"}, Started: jiraTime{started}, TimeSpentSeconds: 60}}
+	for _, loc := range []*time.Location{time.Local, time.UTC, montreal} {
+		tlm, err := timeLogFromJSON(&Issue{Key: "ABC-1"}, logs, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		day := startOfDay(started, loc)
+		b, err := json.Marshal(tlm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back TimeLogMap
+		if err := json.Unmarshal(b, &back); err != nil {
+			t.Fatal(err)
+		}
+		if keys := back.GetSortedKeys(); len(keys) != 1 || keys[0].Format(time.RFC3339) != day.Format(time.RFC3339) {
+			t.Errorf("%s: got days %v after reload, want %s", loc, keys, day)
+		}
+		if again, _ := json.Marshal(back); string(again) != string(b) {
+			t.Errorf("%s: got %s, want %s", loc, again, b)
+		}
+		back, err = UnmarshalTimeLogMap(b, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := back.SumForKey(day); got != 60 {
+			t.Errorf("%s: SumForKey(%s) = %d after reload, want 60", loc, day, got)
+		}
+		tl := back[day][0]
+		if !tl.Started.Equal(started) || tl.Key != "ABC-1" || tl.Author != "bob" || tl.LogId != "1" || tl.Issue != nil {
+			t.Errorf("%s: unexpected log after reload %+v", loc, tl)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package libgojira
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+//Format of the days keying a Timesheet
+const TIMESHEET_DAY_FORMAT = "2006-01-02"
+
+//Range and filters of a Timesheet
+type TimesheetOptions struct {
+	From     time.Time      //First day of the report
+	To       time.Time      //Last day of the report, included
+	Authors  []string       //Only include worklogs by these authors, given as logins, account ids or emails, everyone's when empty
+	Location *time.Location //Time zone worklogs are bucketed by day in, the issues' own when nil
+}
+
+//Time logged per author, per day and per issue over a date range
+type Timesheet struct {
+	Days    []string                             //Every day of the range, in order
+	Authors []string                             //Logins of the authors who logged time, or account ids on Jira Cloud, sorted
+	Names   map[string]string                    //Display names of the authors
+	Issues  []string                             //Keys of the issues time was logged on, sorted
+	Seconds map[string]map[string]map[string]int //Seconds logged, by author, then day, then issue key
+}
+
+//Aggregates the worklogs of the issues and of their subtasks.
+func NewTimesheet(issues []*Issue, opts *TimesheetOptions) *Timesheet {
+	ts := newTimesheet()
+	from := time.Date(opts.From.Year(), opts.From.Month(), opts.From.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(opts.To.Year(), opts.To.Month(), opts.To.Day(), 0, 0, 0, 0, time.UTC)
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		ts.Days = append(ts.Days, d.Format(TIMESHEET_DAY_FORMAT))
+	}
+	if len(ts.Days) == 0 {
+		return ts
+	}
+	first, last := ts.Days[0], ts.Days[len(ts.Days)-1]
+	seenIssues := map[string]bool{}
+	var add func(issue *Issue)
+	add = func(issue *Issue) {
+		if issue == nil || seenIssues[issue.Key] {
+			return
+		}
+		seenIssues[issue.Key] = true
+		for _, logs := range issue.TimeLog {
+			for _, tl := range logs {
+				day := tl.Date.Format(TIMESHEET_DAY_FORMAT)
+				if opts.Location != nil {
+					day = tl.Started.In(opts.Location).Format(TIMESHEET_DAY_FORMAT)
+				}
+				if day < first || day > last || !byAny(tl, opts.Authors) {
+					continue
+				}
+				author := tl.Author
+				if author == "" {
+					author = tl.AuthorAccountId
+				}
+				if tl.AuthorName != "" {
+					ts.Names[author] = tl.AuthorName
+				}
+				ts.add(author, day, issue.Key, tl.Seconds)
+			}
+		}
+		for _, st := range issue.SubTasks {
+			add(st)
+		}
+	}
+	for _, issue := range issues {
+		add(issue)
+	}
+	sort.Strings(ts.Authors)
+	sort.Strings(ts.Issues)
+	return ts
+}
+
+func newTimesheet() *Timesheet {
+	return &Timesheet{Days: []string{}, Authors: []string{}, Names: map[string]string{}, Issues: []string{}, Seconds: map[string]map[string]map[string]int{}}
+}
+
+//Whether the log was written by one of the authors, or there are none.
+func byAny(tl TimeLog, authors []string) bool {
+	for _, a := range authors {
+		if tl.By(a) {
+			return true
+		}
+	}
+	return len(authors) == 0
+}
+
+//Display name of the author, the author itself when unknown.
+func (ts *Timesheet) Name(author string) string {
+	if name := ts.Names[author]; name != "" {
+		return name
+	}
+	return author
+}
+
+func (ts *Timesheet) add(author, day, key string, seconds int) {
+	if _, ok := ts.Seconds[author]; !ok {
+		ts.Seconds[author] = map[string]map[string]int{}
+		ts.Authors = append(ts.Authors, author)
+	}
+	if _, ok := ts.Seconds[author][day]; !ok {
+		ts.Seconds[author][day] = map[string]int{}
+	}
+	if !contains(ts.Issues, key) {
+		ts.Issues = append(ts.Issues, key)
+	}
+	ts.Seconds[author][day][key] += seconds
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+//Builds the timesheet of the issues matching a JQL query.
+func (jc *JiraClient) TimesheetForQuery(jql string, opts *TimesheetOptions) (*Timesheet, error) {
+	return jc.TimesheetForQueryContext(context.Background(), jql, opts)
+}
+
+func (jc *JiraClient) TimesheetForQueryContext(ctx context.Context, jql string, opts *TimesheetOptions) (*Timesheet, error) {
+	issues, err := jc.SearchContext(ctx, &SearchOptions{JQL: jql})
+	if err != nil {
+		return nil, err
+	}
+	return NewTimesheet(issues, opts), nil
+}
+
+//Seconds logged by author on issue over the day
+func (ts *Timesheet) Get(author, day, issue string) int {
+	return ts.Seconds[author][day][issue]
+}
+
+//Seconds logged by author over the day
+func (ts *Timesheet) DayTotal(author, day string) int {
+	total := 0
+	for _, s := range ts.Seconds[author][day] {
+		total += s
+	}
+	return total
+}
+
+//Seconds logged by author on issue over the whole range
+func (ts *Timesheet) IssueTotal(author, issue string) int {
+	total := 0
+	for _, issues := range ts.Seconds[author] {
+		total += issues[issue]
+	}
+	return total
+}
+
+//Seconds logged by author over the whole range
+func (ts *Timesheet) AuthorTotal(author string) int {
+	total := 0
+	for day := range ts.Seconds[author] {
+		total += ts.DayTotal(author, day)
+	}
+	return total
+}
+
+//Seconds logged by everyone over the whole range
+func (ts *Timesheet) Total() int {
+	total := 0
+	for _, author := range ts.Authors {
+		total += ts.AuthorTotal(author)
+	}
+	return total
+}
+
+//Issues author logged time on, sorted
+func (ts *Timesheet) AuthorIssues(author string) []string {
+	result := []string{}
+	for _, issue := range ts.Issues {
+		if ts.IssueTotal(author, issue) > 0 {
+			result = append(result, issue)
+		}
+	}
+	return result
+}
+
+func hours(seconds int) string {
+	if seconds == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.2f", float64(seconds)/3600)
+}
+
+//Writes one table per author, with an issue per row, a day per column and hours in cells.
+func (ts *Timesheet) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	for _, author := range ts.Authors {
+		fmt.Fprintf(tw, "%s\t\n", ts.Name(author))
+		fmt.Fprintf(tw, "Issue\t%s\tTotal\t\n", strings.Join(ts.Days, "\t"))
+		for _, issue := range ts.AuthorIssues(author) {
+			cells := make([]string, 0, len(ts.Days))
+			for _, day := range ts.Days {
+				cells = append(cells, hours(ts.Get(author, day, issue)))
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t\n", issue, strings.Join(cells, "\t"), hours(ts.IssueTotal(author, issue)))
+		}
+		cells := make([]string, 0, len(ts.Days))
+		for _, day := range ts.Days {
+			cells = append(cells, hours(ts.DayTotal(author, day)))
+		}
+		fmt.Fprintf(tw, "Total\t%s\t%s\t\n\t\n", strings.Join(cells, "\t"), hours(ts.AuthorTotal(author)))
+	}
+	fmt.Fprintf(tw, "Total\t%s\t\n", hours(ts.Total()))
+	return tw.Flush()
+}
+
+//Writes a row per author and issue, with hours per day and a total per row.
+//Each author's rows are followed by a total row with an empty issue column.
+func (ts *Timesheet) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(append(append([]string{"Author", "Issue"}, ts.Days...), "Total"))
+	for _, author := range ts.Authors {
+		for _, issue := range ts.AuthorIssues(author) {
+			row := []string{author, issue}
+			for _, day := range ts.Days {
+				row = append(row, hours(ts.Get(author, day, issue)))
+			}
+			cw.Write(append(row, hours(ts.IssueTotal(author, issue))))
+		}
+		row := []string{author, ""}
+		for _, day := range ts.Days {
+			row = append(row, hours(ts.DayTotal(author, day)))
+		}
+		cw.Write(append(row, hours(ts.AuthorTotal(author))))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type timesheetDayJSON struct {
+	Day     string         `json:"day"`
+	Seconds int            `json:"seconds"`
+	Issues  map[string]int `json:"issues"`
+}
+
+type timesheetAuthorJSON struct {
+	Author  string             `json:"author"`
+	Name    string             `json:"name,omitempty"`
+	Seconds int                `json:"seconds"`
+	Days    []timesheetDayJSON `json:"days"`
+}
+
+//Encodes the timesheet as its range, total seconds and, per author, the seconds logged per day and issue.
+func (ts *Timesheet) MarshalJSON() ([]byte, error) {
+	out := struct {
+		From    string                `json:"from,omitempty"`
+		To      string                `json:"to,omitempty"`
+		Seconds int                   `json:"seconds"`
+		Authors []timesheetAuthorJSON `json:"authors"`
+	}{Seconds: ts.Total(), Authors: []timesheetAuthorJSON{}}
+	if len(ts.Days) > 0 {
+		out.From, out.To = ts.Days[0], ts.Days[len(ts.Days)-1]
+	}
+	for _, author := range ts.Authors {
+		a := timesheetAuthorJSON{Author: author, Name: ts.Names[author], Seconds: ts.AuthorTotal(author), Days: []timesheetDayJSON{}}
+		for _, day := range ts.Days {
+			if issues, ok := ts.Seconds[author][day]; ok {
+				a.Days = append(a.Days, timesheetDayJSON{day, ts.DayTotal(author, day), issues})
+			}
+		}
+		out.Authors = append(out.Authors, a)
+	}
+	return json.Marshal(out)
+}
+
+//Decodes a timesheet encoded by MarshalJSON.
+func (ts *Timesheet) UnmarshalJSON(b []byte) error {
+	var in struct {
+		From    string                `json:"from"`
+		To      string                `json:"to"`
+		Authors []timesheetAuthorJSON `json:"authors"`
+	}
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+	*ts = *newTimesheet()
+	if in.From != "" {
+		from, err := time.Parse(TIMESHEET_DAY_FORMAT, in.From)
+		if err != nil {
+			return fmt.Errorf("bad day %q: %w", in.From, err)
+		}
+		to, err := time.Parse(TIMESHEET_DAY_FORMAT, in.To)
+		if err != nil {
+			return fmt.Errorf("bad day %q: %w", in.To, err)
+		}
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			ts.Days = append(ts.Days, d.Format(TIMESHEET_DAY_FORMAT))
+		}
+	}
+	for _, a := range in.Authors {
+		if a.Name != "" {
+			ts.Names[a.Author] = a.Name
+		}
+		for _, d := range a.Days {
+			for key, seconds := range d.Issues {
+				ts.add(a.Author, d.Day, key, seconds)
+			}
+		}
+	}
+	sort.Strings(ts.Authors)
+	sort.Strings(ts.Issues)
+	return nil
+}
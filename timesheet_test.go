@@ -0,0 +1,59 @@
+package libgojira
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimesheetCloudAuthors(t *testing.T) {
+	//Jira Cloud only identifies authors by account id
+	logs := []worklogJSON{
+		{Id: "1", Author: userJSON{AccountId: "a1", DisplayName: "Alice", EmailAddress: "alice@example.com"}, Started: jiraTime{time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)}, TimeSpentSeconds: 3600},
+		{Id: "2", Author: userJSON{AccountId: "b2", DisplayName: "Bob"}, Started: jiraTime{time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)}, TimeSpentSeconds: 1800},
+		{Id: "3", Author: userJSON{AccountId: "a1", DisplayName: "Alice", EmailAddress: "alice@example.com"}, Started: jiraTime{time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC)}, TimeSpentSeconds: 7200},
+	}
+	issue := &Issue{Key: "ABC-1"}
+	tlm, err := timeLogFromJSON(issue, logs, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issue.TimeLog = tlm
+	opts := &TimesheetOptions{From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)}
+	ts := NewTimesheet([]*Issue{issue}, opts)
+	if strings.Join(ts.Authors, ",") != "a1,b2" {
+		t.Fatalf("got authors %q, want a1,b2", ts.Authors)
+	}
+	if ts.AuthorTotal("a1") != 10800 || ts.AuthorTotal("b2") != 1800 || ts.Get("a1", "2024-01-03", "ABC-1") != 7200 {
+		t.Errorf("unexpected seconds %v", ts.Seconds)
+	}
+	if ts.Name("a1") != "Alice" || ts.Name("b2") != "Bob" || ts.Name("c3") != "c3" {
+		t.Errorf("unexpected names %v", ts.Names)
+	}
+	var buf bytes.Buffer
+	if err := ts.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Alice") {
+		t.Errorf("text report doesn't name the authors:\n%s", buf.String())
+	}
+	b, err := json.Marshal(ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Timesheet
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Name("a1") != "Alice" || back.Total() != 12600 {
+		t.Errorf("unexpected timesheet after reload %+v", back)
+	}
+	for _, author := range []string{"a1", "alice@example.com"} {
+		opts.Authors = []string{author}
+		if ts := NewTimesheet([]*Issue{issue}, opts); ts.Total() != 10800 || strings.Join(ts.Authors, ",") != "a1" {
+			t.Errorf("filtered by %s: got %ds by %v, want 10800s by a1", author, ts.Total(), ts.Authors)
+		}
+	}
+}
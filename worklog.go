@@ -0,0 +1,286 @@
+package libgojira
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+//How adding, editing or deleting a worklog affects the issue's remaining estimate
+type AdjustEstimate string
+
+const (
+	AdjustAuto   AdjustEstimate = "auto"   //Reduce the remaining estimate by the time spent (Jira's default)
+	AdjustLeave  AdjustEstimate = "leave"  //Leave the remaining estimate untouched
+	AdjustNew    AdjustEstimate = "new"    //Set the remaining estimate to NewEstimate
+	AdjustManual AdjustEstimate = "manual" //Reduce the remaining estimate by ReduceBy, or increase it on deletion
+)
+
+//Content of a new or edited worklog
+type WorkLogOptions struct {
+	Started        time.Time     //When the work started, now when zero
+	TimeSpent      time.Duration //At least a minute
+	Comment        string
+	Visibility     *Visibility    //Restricts the worklog to a role or group when set
+	AdjustEstimate AdjustEstimate //Jira's default when empty
+	NewEstimate    string         //Remaining estimate in Jira duration syntax ("2d 4h"), for AdjustNew
+	ReduceBy       string         //Estimate adjustment in Jira duration syntax, for AdjustManual
+}
+
+func (wo *WorkLogOptions) params() (url.Values, error) {
+	params := url.Values{}
+	switch wo.AdjustEstimate {
+	case "", AdjustAuto, AdjustLeave:
+	case AdjustNew:
+		if wo.NewEstimate == "" {
+			return nil, &JiraClientError{"NewEstimate is required to adjust the estimate to a new value"}
+		}
+		params.Set("newEstimate", wo.NewEstimate)
+	case AdjustManual:
+		if wo.ReduceBy == "" {
+			return nil, &JiraClientError{"ReduceBy is required to adjust the estimate manually"}
+		}
+		params.Set("reduceBy", wo.ReduceBy)
+	default:
+		return nil, &JiraClientError{fmt.Sprintf("Unknown estimate adjustment %s", wo.AdjustEstimate)}
+	}
+	if wo.AdjustEstimate != "" {
+		params.Set("adjustEstimate", string(wo.AdjustEstimate))
+	}
+	return params, nil
+}
+
+func (wo *WorkLogOptions) payload() msi {
+	started := wo.Started
+	if started.IsZero() {
+		started = time.Now()
+	}
+	m := msi{
+		"started":          started.Format(JIRA_TIME_FORMAT),
+		"timeSpentSeconds": int(wo.TimeSpent / time.Second),
+	}
+	if wo.Comment != "" {
+		m["comment"] = wo.Comment
+	}
+	if wo.Visibility != nil {
+		m["visibility"] = wo.Visibility
+	}
+	return m
+}
+
+func worklogUrl(base string, params url.Values) string {
+	if len(params) == 0 {
+		return base
+	}
+	return base + "?" + params.Encode()
+}
+
+//Logs time on an issue, returning the worklog as created by Jira.
+func (jc *JiraClient) AddWorkLog(issueKey string, opts *WorkLogOptions) (TimeLog, error) {
+	return jc.AddWorkLogContext(context.Background(), issueKey, opts)
+}
+
+func (jc *JiraClient) AddWorkLogContext(ctx context.Context, issueKey string, opts *WorkLogOptions) (TimeLog, error) {
+	if opts.TimeSpent < time.Minute {
+		return TimeLog{}, &JiraClientError{"Time spent must be at least a minute"}
+	}
+	params, err := opts.params()
+	if err != nil {
+		return TimeLog{}, err
+	}
+	var wl worklogJSON
+	if err := jc.sendJSON(ctx, "POST", worklogUrl(fmt.Sprintf("%s/%s/worklog", jc.issueUrl(), issueKey), params), opts.payload(), &wl); err != nil {
+		return TimeLog{}, err
+	}
+	return timeLogFromWorklog(issueKey, nil, &wl, jc.location()), nil
+}
+
+//Replaces the start time, duration, comment and visibility of a worklog.
+//Jira doesn't support AdjustManual when editing a worklog.
+func (jc *JiraClient) UpdateWorkLog(issueKey string, worklog_id string, opts *WorkLogOptions) (TimeLog, error) {
+	return jc.UpdateWorkLogContext(context.Background(), issueKey, worklog_id, opts)
+}
+
+func (jc *JiraClient) UpdateWorkLogContext(ctx context.Context, issueKey string, worklog_id string, opts *WorkLogOptions) (TimeLog, error) {
+	wid, err := numOnly(worklog_id)
+	if err != nil {
+		return TimeLog{}, &JiraClientError{"Bad worklog id"}
+	}
+	if opts.TimeSpent < time.Minute {
+		return TimeLog{}, &JiraClientError{"Time spent must be at least a minute"}
+	}
+	if opts.AdjustEstimate == AdjustManual {
+		return TimeLog{}, &JiraClientError{"Manual estimate adjustment isn't supported when editing a worklog"}
+	}
+	params, err := opts.params()
+	if err != nil {
+		return TimeLog{}, err
+	}
+	var wl worklogJSON
+	if err := jc.sendJSON(ctx, "PUT", worklogUrl(fmt.Sprintf("%s/%s/worklog/%s", jc.issueUrl(), issueKey, wid), params), opts.payload(), &wl); err != nil {
+		return TimeLog{}, err
+	}
+	return timeLogFromWorklog(issueKey, nil, &wl, jc.location()), nil
+}
+
+//Deletes a worklog, adjusting the remaining estimate as described by the AdjustEstimate,
+//NewEstimate and ReduceBy options; the other options are ignored.
+func (jc *JiraClient) DelWorkLogWithOptions(issueKey string, worklog_id string, opts *WorkLogOptions) error {
+	return jc.DelWorkLogWithOptionsContext(context.Background(), issueKey, worklog_id, opts)
+}
+
+func (jc *JiraClient) DelWorkLogWithOptionsContext(ctx context.Context, issueKey string, worklog_id string, opts *WorkLogOptions) error {
+	wid, err := numOnly(worklog_id)
+	if err != nil {
+		return &JiraClientError{"Bad worklog id"}
+	}
+	params, err := opts.params()
+	if err != nil {
+		return err
+	}
+	if opts.AdjustEstimate == AdjustManual {
+		params.Del("reduceBy")
+		params.Set("increaseBy", opts.ReduceBy)
+	}
+	r, err := jc.DeleteContext(ctx, worklogUrl(fmt.Sprintf("%s/%s/worklog/%s", jc.issueUrl(), issueKey, wid), params), "", nil)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(r, nil)
+}
+
+//Fetches every worklog of an issue started at or after since, or all of them when since is zero.
+//Unlike the worklogs embedded in an issue, the list isn't capped by Jira.
+func (jc *JiraClient) GetWorkLogs(issueKey string, since time.Time) ([]TimeLog, error) {
+	return jc.GetWorkLogsContext(context.Background(), issueKey, since)
+}
+
+func (jc *JiraClient) GetWorkLogsContext(ctx context.Context, issueKey string, since time.Time) ([]TimeLog, error) {
+	worklogs, err := jc.getWorklogs(ctx, issueKey, since)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]TimeLog, 0, len(worklogs))
+	for i := range worklogs {
+		result = append(result, timeLogFromWorklog(issueKey, nil, &worklogs[i], jc.location()))
+	}
+	return result, nil
+}
+
+func (jc *JiraClient) getWorklogs(ctx context.Context, issueKey string, since time.Time) ([]worklogJSON, error) {
+	params := url.Values{}
+	if !since.IsZero() {
+		params.Set("startedAfter", strconv.FormatInt(unixMillis(since)-1, 10))
+	}
+	result := []worklogJSON{}
+	startAt := 0
+	for {
+		params.Set("startAt", strconv.Itoa(startAt))
+		var page worklogPageJSON
+		if err := jc.getJSON(ctx, worklogUrl(fmt.Sprintf("%s/%s/worklog", jc.issueUrl(), issueKey), params), &page); err != nil {
+			return nil, err
+		}
+		for _, wl := range page.Worklogs {
+			//Not every Jira version supports startedAfter
+			if since.IsZero() || !wl.Started.Before(since) {
+				result = append(result, wl)
+			}
+		}
+		startAt = page.StartAt + len(page.Worklogs)
+		if len(page.Worklogs) == 0 || startAt >= page.Total {
+			return result, nil
+		}
+	}
+}
+
+//Worklogs changed since a point in time, as returned by GetUpdatedWorkLogIds and GetDeletedWorkLogIds
+type WorkLogChanges struct {
+	Ids   []string  //Ids of the changed worklogs
+	Until time.Time //Time to pass as since to the next call, to continue syncing from there
+}
+
+//Lists the worklogs created or updated since a point in time, to be fetched with GetWorkLogsById.
+func (jc *JiraClient) GetUpdatedWorkLogIds(since time.Time) (*WorkLogChanges, error) {
+	return jc.GetUpdatedWorkLogIdsContext(context.Background(), since)
+}
+
+func (jc *JiraClient) GetUpdatedWorkLogIdsContext(ctx context.Context, since time.Time) (*WorkLogChanges, error) {
+	return jc.worklogChanges(ctx, "updated", since)
+}
+
+//Lists the worklogs deleted since a point in time.
+func (jc *JiraClient) GetDeletedWorkLogIds(since time.Time) (*WorkLogChanges, error) {
+	return jc.GetDeletedWorkLogIdsContext(context.Background(), since)
+}
+
+func (jc *JiraClient) GetDeletedWorkLogIdsContext(ctx context.Context, since time.Time) (*WorkLogChanges, error) {
+	return jc.worklogChanges(ctx, "deleted", since)
+}
+
+func (jc *JiraClient) worklogChanges(ctx context.Context, kind string, since time.Time) (*WorkLogChanges, error) {
+	result := &WorkLogChanges{Ids: []string{}, Until: since}
+	u := jc.apiUrl("worklog/%s?since=%d", kind, unixMillis(since))
+	for {
+		var page worklogChangesJSON
+		if err := jc.getJSON(ctx, u, &page); err != nil {
+			return nil, err
+		}
+		for _, v := range page.Values {
+			result.Ids = append(result.Ids, strconv.FormatInt(v.WorklogId, 10))
+		}
+		if page.Until > 0 {
+			result.Until = time.Unix(0, page.Until*int64(time.Millisecond))
+		}
+		if page.LastPage || page.NextPage == "" {
+			return result, nil
+		}
+		u = page.NextPage
+	}
+}
+
+//Number of worklogs Jira returns at most per call to worklog/list
+const worklogListMax = 1000
+
+//Fetches worklogs by id, e.g. as returned by GetUpdatedWorkLogIds.
+//The returned TimeLogs carry the IssueId of their issue, but no Key.
+func (jc *JiraClient) GetWorkLogsById(ids []string) ([]TimeLog, error) {
+	return jc.GetWorkLogsByIdContext(context.Background(), ids)
+}
+
+func (jc *JiraClient) GetWorkLogsByIdContext(ctx context.Context, ids []string) ([]TimeLog, error) {
+	numids := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		wid, err := numOnly(id)
+		if err != nil {
+			return nil, &JiraClientError{fmt.Sprintf("Bad worklog id %s", id)}
+		}
+		n, _ := strconv.ParseInt(wid, 10, 64)
+		numids = append(numids, n)
+	}
+	result := make([]TimeLog, 0, len(ids))
+	for len(numids) > 0 {
+		chunk := numids
+		if len(chunk) > worklogListMax {
+			chunk = chunk[:worklogListMax]
+		}
+		numids = numids[len(chunk):]
+		var worklogs []worklogJSON
+		if err := jc.sendJSON(ctx, "POST", jc.apiUrl("worklog/list"), msi{"ids": chunk}, &worklogs); err != nil {
+			return nil, err
+		}
+		for i := range worklogs {
+			result = append(result, timeLogFromWorklog("", nil, &worklogs[i], jc.location()))
+		}
+	}
+	return result, nil
+}
+
+//Returns t as milliseconds since the epoch, as used by Jira's worklog endpoints, or 0 for the zero time.
+func unixMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
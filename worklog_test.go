@@ -0,0 +1,175 @@
+package libgojira
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAddWorkLogPayload(t *testing.T) {
+	var payload struct {
+		Started          string `json:"started"`
+		TimeSpentSeconds int    `json:"timeSpentSeconds"`
+	}
+	var query string
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/jira/rest/api/2/issue/ABC-1/worklog" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		query = r.URL.RawQuery
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.Write([]byte(`{"id":"5","started":"2024-01-02T09:00:00.000+0000","timeSpentSeconds":5400,"author":{"name":"me"}}`))
+	})
+	before := time.Now().Add(-time.Second)
+	tl, err := jc.AddWorkLog("ABC-1", &WorkLogOptions{TimeSpent: 90 * time.Minute, AdjustEstimate: AdjustNew, NewEstimate: "1h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	started, err := time.Parse(JIRA_TIME_FORMAT, payload.Started)
+	if err != nil || started.Before(before) || started.After(time.Now()) {
+		t.Errorf("a zero Started should be sent as now, got %q", payload.Started)
+	}
+	if payload.TimeSpentSeconds != 5400 || query != "adjustEstimate=new&newEstimate=1h" {
+		t.Errorf("unexpected payload %+v and query %s", payload, query)
+	}
+	if tl.LogId != "5" || tl.Seconds != 5400 || tl.Key != "ABC-1" {
+		t.Errorf("unexpected worklog %+v", tl)
+	}
+}
+
+func TestWorkLogMinimumDuration(t *testing.T) {
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	})
+	if _, err := jc.AddWorkLog("ABC-1", &WorkLogOptions{TimeSpent: 30 * time.Second}); err == nil {
+		t.Error("AddWorkLog accepted less than a minute")
+	}
+	if _, err := jc.UpdateWorkLog("ABC-1", "5", &WorkLogOptions{}); err == nil {
+		t.Error("UpdateWorkLog accepted no time spent")
+	}
+}
+
+func testWorklog(id int, started string) string {
+	return fmt.Sprintf(`{"id":"%d","issueId":"10001","started":"%s","timeSpentSeconds":60,"author":{"name":"bob"}}`, id, started)
+}
+
+func TestIssueFetchesAllWorklogs(t *testing.T) {
+	pages := map[string]string{
+		"0": `{"startAt":0,"maxResults":2,"total":3,"worklogs":[` + testWorklog(1, "2024-01-02T09:00:00.000+0000") + `,` + testWorklog(2, "2024-01-03T09:00:00.000+0000") + `]}`,
+		"2": `{"startAt":2,"maxResults":2,"total":3,"worklogs":[` + testWorklog(3, "2024-01-04T09:00:00.000+0000") + `]}`,
+	}
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/jira/rest/api/2/issue/ABC-1":
+			//Only the first worklog is embedded
+			w.Write([]byte(`{"id":"10001","key":"ABC-1","fields":{"summary":"Fix it","worklog":{"startAt":0,"maxResults":1,"total":3,"worklogs":[` +
+				testWorklog(1, "2024-01-02T09:00:00.000+0000") + `]}}}`))
+		case "/jira/rest/api/2/issue/ABC-1/worklog":
+			page, ok := pages[r.URL.Query().Get("startAt")]
+			if !ok {
+				t.Errorf("unexpected page %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(page))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	issue, err := jc.GetIssue("ABC-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := issue.TimeLog.SumForMap(); n != 180 {
+		t.Errorf("got %ds of worklogs, want 180", n)
+	}
+	if days := len(issue.TimeLog); days != 3 {
+		t.Errorf("got worklogs on %d days, want 3", days)
+	}
+}
+
+func TestGetWorkLogsSince(t *testing.T) {
+	since := time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC)
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if want := strconv.FormatInt(unixMillis(since)-1, 10); r.URL.Query().Get("startedAfter") != want {
+			t.Errorf("got startedAfter %q, want %s", r.URL.Query().Get("startedAfter"), want)
+		}
+		//Jira versions without startedAfter return every worklog
+		w.Write([]byte(`{"startAt":0,"maxResults":20,"total":3,"worklogs":[` + testWorklog(1, "2024-01-03T08:59:00.000+0000") + `,` +
+			testWorklog(2, "2024-01-03T09:00:00.000+0000") + `,` + testWorklog(3, "2024-01-04T09:00:00.000+0000") + `]}`))
+	})
+	logs, err := jc.GetWorkLogs("ABC-1", since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 || logs[0].LogId != "2" || logs[1].LogId != "3" || logs[0].Key != "ABC-1" {
+		t.Errorf("unexpected worklogs %+v", logs)
+	}
+}
+
+func TestGetUpdatedWorkLogIdsFollowsPages(t *testing.T) {
+	since := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jira/rest/api/2/worklog/updated" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		switch r.URL.Query().Get("since") {
+		case strconv.FormatInt(unixMillis(since), 10):
+			next := "http://" + r.Host + "/jira/rest/api/2/worklog/updated?since=1704243600000"
+			w.Write([]byte(`{"values":[{"worklogId":1},{"worklogId":2}],"since":1704153600000,"until":1704243600000,"lastPage":false,"nextPage":"` + next + `"}`))
+		case "1704243600000":
+			w.Write([]byte(`{"values":[{"worklogId":3}],"since":1704243600000,"until":1704330000000,"lastPage":true}`))
+		default:
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+	})
+	changes, err := jc.GetUpdatedWorkLogIds(since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(changes.Ids, ",") != "1,2,3" {
+		t.Errorf("got ids %v, want 1,2,3", changes.Ids)
+	}
+	if want := time.Unix(1704330000, 0); !changes.Until.Equal(want) {
+		t.Errorf("got until %s, want %s", changes.Until, want)
+	}
+}
+
+func TestGetWorkLogsByIdChunks(t *testing.T) {
+	chunks := []int{}
+	jc := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/jira/rest/api/2/worklog/list" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct{ Ids []int }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		chunks = append(chunks, len(body.Ids))
+		logs := []string{}
+		for _, id := range body.Ids {
+			logs = append(logs, testWorklog(id, "2024-01-02T09:00:00.000+0000"))
+		}
+		w.Write([]byte("[" + strings.Join(logs, ",") + "]"))
+	})
+	ids := []string{}
+	for i := 1; i <= 2500; i++ {
+		ids = append(ids, strconv.Itoa(i))
+	}
+	logs, err := jc.GetWorkLogsById(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(chunks) != "[1000 1000 500]" {
+		t.Errorf("got chunks of %v ids, want [1000 1000 500]", chunks)
+	}
+	if len(logs) != 2500 || logs[2499].LogId != "2500" || logs[0].IssueId != "10001" {
+		t.Errorf("got %d worklogs, want 2500", len(logs))
+	}
+	if _, err := jc.GetWorkLogsById([]string{"abc"}); err == nil {
+		t.Error("no error for a bad id")
+	}
+}